@@ -2,11 +2,16 @@ package rhttp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
+	"time"
 )
 
 // httpClientInterface defines the interface that this package depends upon to
@@ -22,6 +27,17 @@ type httpClientInterface interface {
 // instantiated as the inner http client
 type Client struct {
 	ci httpClientInterface
+
+	baseURL     *url.URL
+	header      http.Header
+	middleware  []Middleware
+	chain       httpClientInterface
+	retryPolicy *RetryPolicy
+	checkStatus bool
+	codecs      map[string]Codec
+
+	maxResponseBytes int64
+	decodeOptions    []DecodeOption
 }
 
 // NewClient vends a `*Client` that wraps the provided `httpClientInterface`
@@ -39,6 +55,54 @@ func (c *Client) lazyInitialize() {
 	}
 }
 
+// WithBaseURL sets the url against which relative request urls are resolved,
+// e.g. with a base url of `https://example.com/api`, a request for `/v1/users`
+// targets `https://example.com/api/v1/users`. Query parameters of the base url
+// are merged into those of every request. Absolute request urls are left
+// as-is. Providing a nil url removes the base url.
+func (c *Client) WithBaseURL(u *url.URL) *Client {
+	if u == nil {
+		c.baseURL = nil
+		return c
+	}
+
+	base := *u
+	c.baseURL = &base
+
+	return c
+}
+
+// WithDefaultHeader adds the provided header to every request subsequently
+// initialized by the client. A request may override a default header by
+// specifying any value(s) for the same header key.
+func (c *Client) WithDefaultHeader(key, value string) *Client {
+	if c.header == nil {
+		c.header = make(http.Header)
+	}
+	c.header.Add(key, value)
+
+	return c
+}
+
+// WithDefaultHeaders is like `WithDefaultHeader`, for each of the provided
+// headers
+func (c *Client) WithDefaultHeaders(header http.Header) *Client {
+	for key, values := range header {
+		for _, value := range values {
+			c = c.WithDefaultHeader(key, value)
+		}
+	}
+
+	return c
+}
+
+// WithStatusCheck enables or disables the status check of every request
+// subsequently initialized by the client (see `Request.WithStatusCheck`)
+func (c *Client) WithStatusCheck(enabled bool) *Client {
+	c.checkStatus = enabled
+	return c
+}
+
 // GET initializes an HTTP GET `*Request` targeting the provided url. The
 // caller can now chain request preparation functions.
 func (c *Client) GET(u *url.URL) *Request {
@@ -75,12 +139,90 @@ func (c *Client) DELETE(u *url.URL) *Request {
 	return c.NewRequest(http.MethodDelete, u)
 }
 
+// GETPath initializes an HTTP GET `*Request` targeting the provided path,
+// resolved against the client's base url. The caller can now chain request
+// preparation functions.
+func (c *Client) GETPath(path string) *Request {
+	return c.NewRequestPath(http.MethodGet, path)
+}
+
+// HEADPath initializes an HTTP HEAD `*Request` targeting the provided path,
+// resolved against the client's base url. The caller can now chain request
+// preparation functions.
+func (c *Client) HEADPath(path string) *Request {
+	return c.NewRequestPath(http.MethodHead, path)
+}
+
+// POSTPath initializes an HTTP POST `*Request` targeting the provided path,
+// resolved against the client's base url. The caller can now chain request
+// preparation functions.
+func (c *Client) POSTPath(path string) *Request {
+	return c.NewRequestPath(http.MethodPost, path)
+}
+
+// PUTPath initializes an HTTP PUT `*Request` targeting the provided path,
+// resolved against the client's base url. The caller can now chain request
+// preparation functions.
+func (c *Client) PUTPath(path string) *Request {
+	return c.NewRequestPath(http.MethodPut, path)
+}
+
+// PATCHPath initializes an HTTP PATCH `*Request` targeting the provided path,
+// resolved against the client's base url. The caller can now chain request
+// preparation functions.
+func (c *Client) PATCHPath(path string) *Request {
+	return c.NewRequestPath(http.MethodPatch, path)
+}
+
+// DELETEPath initializes an HTTP DELETE `*Request` targeting the provided
+// path, resolved against the client's base url. The caller can now chain
+// request preparation functions.
+func (c *Client) DELETEPath(path string) *Request {
+	return c.NewRequestPath(http.MethodDelete, path)
+}
+
+// NewRequestPath initializes an HTTP `*Request` ready to use the provided
+// request method and targeting the provided path (which may include a query
+// string), resolved against the client's base url. If the path cannot be
+// parsed, the error is returned once the request is executed. The caller can
+// now chain request preparation functions.
+func (c *Client) NewRequestPath(method string, path string) *Request {
+	u, err := url.Parse(path)
+	if err != nil {
+		c.lazyInitialize()
+		r := makeRequest(context.Background(), c.ci, method, &url.URL{})
+		r.err = fmt.Errorf("failed to parse path for '%s %s': %w", method, path, err)
+		return r
+	}
+
+	return c.NewRequest(method, u)
+}
+
 // NewRequest initialize an HTTP `*Request` ready to use the provided request
 // method and targeting the provided url. The caller can now chain request
 // preparation functions.
 func (c *Client) NewRequest(method string, u *url.URL) *Request {
+	return c.NewRequestContext(context.Background(), method, u)
+}
+
+// NewRequestContext is like `NewRequest`, but the provided context governs the
+// lifetime of the request, i.e. cancelling the context or exceeding its
+// deadline aborts the request, even if it is in-flight. If the client has a
+// base url, a relative url is resolved against it.
+func (c *Client) NewRequestContext(ctx context.Context, method string, u *url.URL) *Request {
 	c.lazyInitialize()
-	return makeRequest(c.ci, method, u)
+	ci := c.ci
+	if c.chain != nil {
+		ci = c.chain
+	}
+	r := makeRequest(ctx, ci, method, resolveURL(c.baseURL, u))
+	r.defaultHeader = c.header.Clone()
+	r.retryPolicy = c.retryPolicy
+	r.checkStatus = c.checkStatus
+	r.codecs = c.registry()
+	r.maxResponseBytes = c.maxResponseBytes
+	r.decodeOptions = c.decodeOptions
+	return r
 }
 
 // Request holds the details necessary to later prepare an `*http.Request` and
@@ -92,37 +234,249 @@ type Request struct {
 	ci  httpClientInterface
 	err error
 
-	method  string
-	u       *url.URL
-	reqbody io.ReadCloser
+	ctx        context.Context
+	method     string
+	u          *url.URL
+	pathParams map[string]string
+
+	// the request body is either a one-shot reader or, if it may be replayed,
+	// a function vending a fresh reader of the known length
+	reqbody       io.ReadCloser
+	getBody       func() (io.ReadCloser, error)
+	contentLength int64
+	contentType   string
+
+	header        http.Header
+	defaultHeader http.Header
+
+	prepareCBs []func(*http.Request) error
+
+	retryPolicy *RetryPolicy
+	checkStatus bool
+	codecs      map[string]Codec
 
-	prepareCB func(*http.Request) error
+	maxResponseBytes int64
+	decodeOptions    []DecodeOption
 }
 
 // makeRequest is a convenience function for instantiating a `*Request`
 func makeRequest(
+	ctx context.Context,
 	ci httpClientInterface,
 	method string,
 	u *url.URL,
 ) *Request {
-	return &Request{
+	r := &Request{
 		ci:     ci,
+		ctx:    ctx,
 		method: method,
 		u:      u,
+		codecs: defaultCodecs,
+	}
+
+	if u == nil {
+		r.u = &url.URL{}
+		r.err = fmt.Errorf("nil url for '%s'", method)
+	} else if ctx == nil {
+		r.err = fmt.Errorf("nil context for '%s %s'", method, u)
+	}
+
+	return r
+}
+
+// WithContext sets the context that governs the lifetime of the request,
+// replacing any context provided when the request was initialized.
+func (r *Request) WithContext(ctx context.Context) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	if ctx == nil {
+		r.err = fmt.Errorf("nil context for '%s %s'", r.method, r.u)
+		return r
+	}
+
+	r.ctx = ctx
+
+	return r
+}
+
+// WithPathParam fills the `{name}` placeholder(s) in the request url's path
+// with the provided value, which is percent-escaped as a single path segment.
+// It is an error if the path has no such placeholder, if the value is empty or
+// a dot segment, i.e. `.` or `..`, which escaping cannot neutralize, or if any
+// placeholder remains unfilled once the request is executed.
+func (r *Request) WithPathParam(name, value string) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	found := false
+	for _, placeholder := range pathParamNames(r.u) {
+		if placeholder == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		r.err = fmt.Errorf("no path parameter '{%s}' for '%s %s'", name, r.method, r.u)
+		return r
+	}
+
+	if value == "" {
+		r.err = fmt.Errorf("empty value for path parameter '{%s}' for '%s %s'", name, r.method, r.u)
+		return r
+	}
+
+	if value == "." || value == ".." {
+		r.err = fmt.Errorf("dot segment '%s' for path parameter '{%s}' for '%s %s'", value, name, r.method, r.u)
+		return r
+	}
+
+	if r.pathParams == nil {
+		r.pathParams = make(map[string]string)
+	}
+	r.pathParams[name] = value
+
+	return r
+}
+
+// WithPathParams is like `WithPathParam`, for each of the provided name-value
+// pairs
+func (r *Request) WithPathParams(params map[string]string) *Request {
+	// sort the names so that the first error encountered is deterministic
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		r = r.WithPathParam(name, params[name])
 	}
+
+	return r
 }
 
+// WithQueryParam adds the provided key-value pair to the query parameters of
+// the request url. The url provided when the request was initialized is not
+// mutated.
 func (r *Request) WithQueryParam(key, value string) *Request {
 	// do nothing if there is already an error preparing this request
 	if r.err != nil {
 		return r
 	}
 
-	r.u.Query().Add(key, value)
+	query := r.u.Query()
+	query.Add(key, value)
+	r.u.RawQuery = query.Encode()
+
+	return r
+}
+
+// WithQuery adds all of the provided values to the query parameters of the
+// request url
+func (r *Request) WithQuery(values url.Values) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	query := r.u.Query()
+	for key, vals := range values {
+		for _, val := range vals {
+			query.Add(key, val)
+		}
+	}
+	r.u.RawQuery = query.Encode()
 
 	return r
 }
 
+// EncodeQuery encodes the fields of the provided struct as query parameters
+// and adds them to the request url. Fields are encoded according to their
+// `query:"name,omitempty"` struct tags. Slices are encoded as repeated
+// parameters or, with the `comma` tag option, as a single comma-joined value.
+// A `time.Time` is encoded as RFC 3339, unless the field also has a `layout`
+// struct tag or the `unix` or `unixmilli` tag option. The fields of embedded
+// structs are encoded as if they belonged to the outer struct.
+func (r *Request) EncodeQuery(v interface{}) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	values, err := encodeQuery(v)
+	if err != nil {
+		r.err = fmt.Errorf("failed to encode query for '%s %s': %w", r.method, r.u, err)
+		return r
+	}
+
+	return r.WithQuery(values)
+}
+
+// WithHeader adds the provided header to the request, appending to any
+// existing values for the same key. Any value(s) specified for a key on the
+// request replace the client's default value(s) for that key.
+func (r *Request) WithHeader(key, value string) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	if r.header == nil {
+		r.header = make(http.Header)
+	}
+	r.header.Add(key, value)
+
+	return r
+}
+
+// SetHeader sets the provided header on the request, replacing any existing
+// values for the same key
+func (r *Request) SetHeader(key, value string) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	if r.header == nil {
+		r.header = make(http.Header)
+	}
+	r.header.Set(key, value)
+
+	return r
+}
+
+// WithHeaders is like `WithHeader`, for each of the provided headers
+func (r *Request) WithHeaders(header http.Header) *Request {
+	for key, values := range header {
+		for _, value := range values {
+			r = r.WithHeader(key, value)
+		}
+	}
+
+	return r
+}
+
+// Accept sets the `Accept` header of the request to the provided media types,
+// declaring up front which content types the response may be decoded from
+func (r *Request) Accept(mediaTypes ...string) *Request {
+	return r.SetHeader("Accept", strings.Join(mediaTypes, ", "))
+}
+
+// AcceptJSON sets the `Accept` header of the request to `application/json`
+func (r *Request) AcceptJSON() *Request {
+	return r.Accept("application/json")
+}
+
+// AcceptXML sets the `Accept` header of the request to `application/xml`
+func (r *Request) AcceptXML() *Request {
+	return r.Accept("application/xml")
+}
+
 // WithRequestBody allows the consumer to specify any request body
 func (r *Request) WithRequestBody(reqbody io.ReadCloser) *Request {
 	// do nothing if there is already an error preparing this request
@@ -131,48 +485,156 @@ func (r *Request) WithRequestBody(reqbody io.ReadCloser) *Request {
 	}
 
 	r.reqbody = reqbody
+	r.getBody = nil
+	r.contentLength = 0
+	r.contentType = ""
 
 	return r
 }
 
+// setBytesBody sets the provided bytes as the replayable request body. The
+// content type is left as-is, since it still describes buffered bodies.
+func (r *Request) setBytesBody(buf []byte) {
+	r.reqbody = nil
+	r.getBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}
+	r.contentLength = int64(len(buf))
+}
+
 // EncodeJSON encodes the provided `reqbody` struct to JSON and sets it as the
-// reqbody of the HTTP request
+// reqbody of the HTTP request. Unless a `Content-Type` header is specified
+// otherwise, it is set to `application/json`.
 func (r *Request) EncodeJSON(reqbody interface{}) *Request {
 	// do nothing if there is already an error preparing this request
 	if r.err != nil {
 		return r
 	}
 
+	return r.encodeWith(jsonCodec{}, reqbody, jsonCodec{}.ContentType())
+}
+
+// Encode encodes the provided `reqbody` with the codec registered on the client
+// for the provided content type and sets it as the reqbody of the HTTP
+// request. Unless a `Content-Type` header is specified otherwise, it is set to
+// the provided content type.
+func (r *Request) Encode(reqbody interface{}, contentType string) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	codec, err := lookupCodec(r.codecs, contentType)
+	if err != nil {
+		r.err = fmt.Errorf("failed to encode body for '%s %s': %w", r.method, r.u, err)
+		return r
+	}
+
+	return r.encodeWith(codec, reqbody, contentType)
+}
+
+// EncodeXML encodes the provided `reqbody` struct to XML, preceded by the
+// standard XML header, and sets it as the reqbody of the HTTP request. Unless
+// a `Content-Type` header is specified otherwise, it is set to
+// `application/xml; charset=utf-8`.
+func (r *Request) EncodeXML(reqbody interface{}) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	return r.encodeWith(xmlCodec{}, reqbody, xmlCodec{}.ContentType())
+}
+
+// EncodeForm URL-encodes the provided form values and sets them as the reqbody
+// of the HTTP request. Unless a `Content-Type` header is specified otherwise,
+// it is set to `application/x-www-form-urlencoded`.
+func (r *Request) EncodeForm(values url.Values) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	return r.encodeWith(formCodec{}, values, FormContentType)
+}
+
+// EncodeFormStruct URL-encodes the fields of the provided struct and sets them
+// as the reqbody of the HTTP request. Fields are encoded according to the same
+// `query:"name,omitempty"` struct tags as `EncodeQuery`. Unless a
+// `Content-Type` header is specified otherwise, it is set to
+// `application/x-www-form-urlencoded`.
+func (r *Request) EncodeFormStruct(v interface{}) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	return r.encodeWith(formCodec{}, v, FormContentType)
+}
+
+// encodeWith encodes the provided `reqbody` with the codec and sets it as the
+// reqbody of the HTTP request, with the provided content type
+func (r *Request) encodeWith(codec Codec, reqbody interface{}, contentType string) *Request {
 	var buf bytes.Buffer
-	err := json.NewEncoder(&buf).Encode(reqbody)
+	err := codec.Encode(&buf, reqbody)
 	if err != nil {
-		r.err = fmt.Errorf("failed to encode body for '%s %s': %w", r.method, r.u.String(), err)
+		r.err = fmt.Errorf("failed to encode body for '%s %s': %w", r.method, r.u, err)
 		return r
 	}
 
-	r.reqbody = io.NopCloser(&buf)
+	r.setBytesBody(buf.Bytes())
+	r.contentType = contentType
 
 	return r
 }
 
-// Prepare defines a callback that will be invoked during the preparation
-// phase, i.e. just before `Do()` is invoked on the inner
-// `httpClientInterface`. It is recommended that the consumer does not
-// manipulate the request body during this callback.
+// Prepare adds a callback that will be invoked during the preparation phase,
+// i.e. just before `Do()` is invoked on the inner `httpClientInterface`.
+// Callbacks are invoked in the order they are added and the first callback to
+// return an error aborts the request. It is recommended that the consumer does
+// not manipulate the request body during these callbacks.
 func (r *Request) Prepare(prepareCB func(*http.Request) error) *Request {
 	// do nothing if there is already an error preparing this request
 	if r.err != nil {
-		return nil
+		return r
 	}
 
-	r.prepareCB = prepareCB
+	if prepareCB == nil {
+		r.err = fmt.Errorf("nil prepare callback for '%s %s'", r.method, r.u)
+		return r
+	}
+
+	r.prepareCBs = append(r.prepareCBs, prepareCB)
 	return r
 }
 
+// WithStatusCheck enables or disables the status check of the response,
+// overriding the client's setting. With the status check enabled, a response
+// with a non-2xx status code yields an `*Error` carrying the status code and,
+// as its message, the response body text. The response is still returned
+// alongside the error and its body may still be read.
+func (r *Request) WithStatusCheck(enabled bool) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	r.checkStatus = enabled
+	return r
+}
+
+// DoContext is shorthand for `WithContext(ctx).Do()`
+func (r *Request) DoContext(ctx context.Context) *Result {
+	return r.WithContext(ctx).Do()
+}
+
 // Do the `*Request` embodied within, returning a `*Result` for the caller to
-// consume
+// consume. If the request's context is done before or during execution, the
+// returned error wraps `context.Canceled` or `context.DeadlineExceeded`
+// accordingly.
 func (r *Request) Do() *Result {
 	if r.err != nil {
+		r.closeBody()
 		return &Result{
 			request:  r,
 			response: nil,
@@ -180,37 +642,37 @@ func (r *Request) Do() *Result {
 		}
 	}
 
-	urlstr := r.u.String()
-	req, err := http.NewRequest(r.method, urlstr, r.reqbody)
+	req, err := r.prepare()
 	if err != nil {
+		r.closeBody()
 		return &Result{
 			request:  r,
 			response: nil,
-			err:      fmt.Errorf("failed to prepare request for '%s %s': %w", r.method, urlstr, err),
+			err:      err,
 		}
 	}
 
-	if req == nil {
+	if err := r.ctx.Err(); err != nil {
+		r.closeBody()
 		return &Result{
 			request:  r,
 			response: nil,
-			err:      fmt.Errorf("expected a non-nil request for '%s %s'", r.method, urlstr),
+			err:      fmt.Errorf("request context done before execution for '%s %v': %w", r.method, req.URL, err),
 		}
 	}
 
-	if r.prepareCB != nil {
-		err = r.prepareCB(req)
-		if err != nil {
+	resp, err := r.execute(req)
+	if err != nil {
+		// the inner client may not wrap the context error itself, so make sure
+		// the caller can always distinguish cancellation from other failures
+		if ctxErr := r.ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
 			return &Result{
 				request:  r,
 				response: nil,
-				err:      fmt.Errorf("failed to execute the prepare callback for '%s %s': %w", r.method, urlstr, err),
+				err:      fmt.Errorf("request context done for '%s %v' (%v): %w", r.method, req.URL, err, ctxErr),
 			}
 		}
-	}
 
-	resp, err := r.ci.Do(req)
-	if err != nil {
 		return &Result{
 			request:  r,
 			response: nil,
@@ -218,20 +680,243 @@ func (r *Request) Do() *Result {
 		}
 	}
 
+	if resp != nil {
+		err = limitResponseBody(resp, r.maxResponseBytes)
+		if err != nil {
+			return &Result{
+				request:     r,
+				httpRequest: req,
+				response:    resp,
+				err:         fmt.Errorf("failed to receive response for '%s %v': %w", r.method, req.URL, err),
+			}
+		}
+	}
+
+	if r.checkStatus && resp != nil {
+		// N.B. the resulting `*Error` already identifies the request, so it is
+		// returned as-is
+		err = checkStatus(req, resp, r.maxResponseBytes)
+		if err != nil {
+			return &Result{
+				request:     r,
+				httpRequest: req,
+				response:    resp,
+				err:         err,
+			}
+		}
+	}
+
 	return &Result{
-		request:  r,
-		response: resp,
-		err:      nil,
+		request:     r,
+		httpRequest: req,
+		response:    resp,
+		err:         nil,
+	}
+}
+
+// closeBody releases a one-shot request body that will never be sent, e.g.
+// one whose reader holds an open file
+func (r *Request) closeBody() {
+	if r.reqbody != nil {
+		r.reqbody.Close()
+	}
+}
+
+// maxErrorBodyBytes bounds how much of an unsuccessful response's body is read
+// into the message of the resulting `*Error`
+const maxErrorBodyBytes = 64 << 10
+
+// checkStatus returns an `*Error` if the response has a non-2xx status code.
+// In that case, the beginning of the response body is buffered, and put back
+// in front of the rest, so that the full body may still be read by the caller.
+// No more of the body is buffered than the response size limit, if any.
+func checkStatus(req *http.Request, resp *http.Response, maxResponseBytes int64) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	maxBytes := int64(maxErrorBodyBytes)
+	if maxResponseBytes > 0 && maxResponseBytes < maxBytes {
+		maxBytes = maxResponseBytes
+	}
+
+	var buf []byte
+	if resp.Body != nil {
+		var err error
+		buf, err = io.ReadAll(io.LimitReader(resp.Body, maxBytes))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.MultiReader(bytes.NewReader(buf), resp.Body),
+			Closer: resp.Body,
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read body of response with status code %d: %w", resp.StatusCode, err)
+		}
+	}
+
+	return newStatusError(req, resp, buf)
+}
+
+// maxErrorSnippetBytes bounds how much of an unsuccessful response's body is
+// kept as the `Body` of the resulting `*Error`
+const maxErrorSnippetBytes = 1 << 10
+
+// newStatusError creates an `*Error` for an unsuccessful response to the
+// provided request. A problem details document is decoded into the error,
+// while any other response body text is used as the message.
+func newStatusError(req *http.Request, resp *http.Response, body []byte) *Error {
+	statusCode := resp.StatusCode
+
+	var httpErr *Error
+	if isProblem(resp.Header) {
+		if problem, err := parseProblem(statusCode, body); err == nil {
+			httpErr = problem
+		}
+	}
+
+	if httpErr == nil {
+		message := strings.TrimSpace(string(body))
+		if message == "" {
+			message = http.StatusText(statusCode)
+		}
+		httpErr = NewError(statusCode, message)
+	}
+
+	if req == nil {
+		req = resp.Request
+	}
+	if req != nil {
+		httpErr.Method = req.Method
+		if req.URL != nil {
+			httpErr.URL = req.URL.Redacted()
+		}
+	}
+
+	httpErr.ResponseHeader = resp.Header.Clone()
+
+	snippet := body
+	if len(snippet) > maxErrorSnippetBytes {
+		snippet = snippet[:maxErrorSnippetBytes]
+	}
+	httpErr.Body = strings.ToValidUTF8(string(snippet), "")
+
+	return httpErr
+}
+
+// prepare builds the `*http.Request` embodied within and invokes the prepare
+// callbacks upon it
+func (r *Request) prepare() (*http.Request, error) {
+	u, err := fillPathParams(r.u, r.pathParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare url for '%s %s': %w", r.method, r.u, err)
+	}
+
+	urlstr := u.String()
+
+	// a one-shot body must be buffered if the request may be retried
+	if r.getBody == nil && r.reqbody != nil && r.retryPolicy.allowsRetries(r.method) {
+		buf, err := io.ReadAll(r.reqbody)
+		r.reqbody.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to buffer request body for '%s %s': %w", r.method, urlstr, err)
+		}
+		r.setBytesBody(buf)
+	}
+
+	body := r.reqbody
+	if r.getBody != nil {
+		body, err = r.getBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get request body for '%s %s': %w", r.method, urlstr, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(r.ctx, r.method, urlstr, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare request for '%s %s': %w", r.method, urlstr, err)
+	}
+
+	if req == nil {
+		return nil, fmt.Errorf("expected a non-nil request for '%s %s'", r.method, urlstr)
+	}
+
+	if r.getBody != nil {
+		req.GetBody = r.getBody
+		req.ContentLength = r.contentLength
+	}
+
+	for key, values := range r.defaultHeader {
+		if _, overridden := r.header[key]; !overridden {
+			req.Header[key] = append([]string(nil), values...)
+		}
+	}
+	for key, values := range r.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	// the content type of an encoded body is a request-level value, so it
+	// overrides the client's default headers, but not the request's own
+	if r.contentType != "" && r.header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+
+	for i, prepareCB := range r.prepareCBs {
+		err = prepareCB(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute prepare callback #%d for '%s %s': %w", i, r.method, urlstr, err)
+		}
+	}
+
+	return req, nil
+}
+
+// execute does the prepared request with the inner http client, retrying per
+// the request's retry policy. Each retry uses a clone of the prepared request
+// with a fresh body.
+func (r *Request) execute(req *http.Request) (*http.Response, error) {
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		resp, err := r.ci.Do(attemptReq)
+		if err == nil && resp == nil {
+			return nil, nil
+		}
+
+		delay, retry := r.retryPolicy.retryDelay(attempt, req, resp, err)
+		replayable := req.GetBody != nil || req.Body == nil || req.Body == http.NoBody
+		if !retry || !replayable {
+			return resp, err
+		}
+
+		if resp != nil && resp.Body != nil {
+			// drain the body so that the connection may be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, fmt.Errorf("aborted retry after attempt #%d: %w", attempt, err)
+		}
+
+		attemptReq = req.Clone(req.Context())
+		if req.GetBody != nil {
+			attemptReq.Body, err = req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get request body for attempt #%d: %w", attempt+1, err)
+			}
+		}
 	}
 }
 
 // Result contains the output of executing `Do()` on a `*Request`. There may
 // have been an error doing the request, or perhaps an error further upstream,
-// so the `response` ptr is non-nil if and only if `err` is nil
+// so the `response` ptr is non-nil only if an HTTP response was received. Both
+// are non-nil if the response failed the status check.
 type Result struct {
-	request  *Request // back-pointer to the originating request
-	response *http.Response
-	err      error
+	request     *Request      // back-pointer to the originating request
+	httpRequest *http.Request // the prepared request, if it was executed
+	response    *http.Response
+	err         error
 }
 
 // Response returns the underlying HTTP response, which is useful to consumers
@@ -252,6 +937,26 @@ func (r *Result) Response() (*http.Response, error) {
 	return r.response, nil
 }
 
+// closeResponse closes the body of a response that accompanies an error, e.g.
+// one that failed the status check, since the caller will not read it
+func (r *Result) closeResponse() {
+	if r.response != nil && r.response.Body != nil {
+		r.response.Body.Close()
+	}
+}
+
+// RetryAfter returns the delay advised by the response's `Retry-After` header,
+// which may specify either a number of seconds or an HTTP-date. The boolean is
+// false if there is no response, no such header, or it is malformed. Unlike
+// the other methods of `*Result`, this does not terminate a call chain.
+func (r *Result) RetryAfter() (time.Duration, bool) {
+	if r.response == nil {
+		return 0, false
+	}
+
+	return parseRetryAfter(r.response.Header.Get("Retry-After"), time.Now())
+}
+
 // RawBytes reads the entire response body into a slice of bytes and returns
 // it. If there was an error anywhere in the chain, it is returned. As long as
 // an HTTP response was generated, it is returned. However, note that this
@@ -259,6 +964,7 @@ func (r *Result) Response() (*http.Response, error) {
 // chain.
 func (r *Result) RawBytes() (*http.Response, []byte, error) {
 	if r.err != nil {
+		r.closeResponse()
 		return r.response, nil, r.err
 	}
 
@@ -278,6 +984,7 @@ func (r *Result) RawBytes() (*http.Response, []byte, error) {
 // chain.
 func (r *Result) StreamResponse(dst io.Writer) (*http.Response, error) {
 	if r.err != nil {
+		r.closeResponse()
 		return r.response, r.err
 	}
 
@@ -296,12 +1003,125 @@ func (r *Result) StreamResponse(dst io.Writer) (*http.Response, error) {
 }
 
 // DecodeJSON attempts to decode the response body into the provided interface
-// `v`. If there was an error anywhere in the chain, it is returned. As long as
-// an HTTP response was generated, it is returned. However, note that this
-// method reads and closes the response body. This method terminates a call
+// `v`, as configured by the provided options. If there was an error anywhere in
+// the chain, it is returned. As long as an HTTP response was generated, it is
+// returned. However, note that this method reads and closes the response body.
+// This method terminates a call chain.
+func (r *Result) DecodeJSON(v interface{}, opts ...DecodeOption) (*http.Response, error) {
+	return r.decodeWith(v, opts, func(body io.Reader, o decodeOptions) error {
+		return o.decodeJSON(body, v)
+	})
+}
+
+// DecodeJSONOrError is like `DecodeJSON`, except that a response with a non-2xx
+// status code is decoded into the provided `failure` interface rather than
+// `success`. In that case, an `*Error` is returned, carrying the status code,
+// the response body text as its message and, provided the body could be
+// decoded, `failure` as its `Payload` - and as its `Cause`, if `failure` is
+// itself an error. Either may be retrieved with `errors.As`. If a non-empty
+// body could not be decoded, the decoding error is the `Cause` instead. The
+// options apply to decoding `success` only. This method terminates a call
 // chain.
-func (r *Result) DecodeJSON(v interface{}) (*http.Response, error) {
+func (r *Result) DecodeJSONOrError(success interface{}, failure interface{}, opts ...DecodeOption) (*http.Response, error) {
+	if r.response == nil || r.response.StatusCode >= 200 && r.response.StatusCode < 300 {
+		return r.DecodeJSON(success, opts...)
+	}
+
+	// the status check may have already turned the response into an error,
+	// but any other error takes precedence
+	var httpErr *Error
+	if r.err != nil && !errors.As(r.err, &httpErr) {
+		r.closeResponse()
+		return r.response, r.err
+	}
+
+	defer r.response.Body.Close()
+
+	buf, err := io.ReadAll(io.LimitReader(r.response.Body, maxErrorBodyBytes))
+	if err != nil {
+		return r.response, fmt.Errorf("failed to read the response body for '%s %s': %w", r.request.method, r.request.u, err)
+	}
+
+	httpErr = newStatusError(r.httpRequest, r.response, buf)
+	if failure != nil && len(bytes.TrimSpace(buf)) > 0 {
+		err = json.Unmarshal(buf, failure)
+		if err != nil {
+			// N.B. record the failure, so that a malformed body can be told
+			// apart from a body without structured details
+			httpErr.Cause = fmt.Errorf("failed to decode the response body into '%T': %w", failure, err)
+		} else {
+			httpErr.Payload = failure
+			if cause, ok := failure.(error); ok {
+				httpErr.Cause = cause
+			}
+		}
+	}
+
+	return r.response, httpErr
+}
+
+// Decode decodes the response body into the provided interface `v`, using the
+// codec registered on the client for the response's `Content-Type`. The
+// provided options configure the decoding as for `DecodeJSON` if the JSON codec
+// is used; for any other codec, only `RequireContentType` applies. If there was
+// an error anywhere in the chain, it is returned. As long as an HTTP response
+// was generated, it is returned. However, note that this method reads and
+// closes the response body. This method terminates a call chain.
+func (r *Result) Decode(v interface{}, opts ...DecodeOption) (*http.Response, error) {
+	return r.decodeWith(v, opts, func(body io.Reader, o decodeOptions) error {
+		contentType := r.response.Header.Get("Content-Type")
+		codec, err := lookupCodec(r.request.codecs, contentType)
+		if err != nil {
+			return err
+		}
+
+		// the built-in codecs are given what the `Codec` interface cannot
+		// convey, i.e. the options and the charset of the response
+		switch codec.(type) {
+		case jsonCodec:
+			return o.decodeJSON(body, v)
+		case xmlCodec:
+			return decodeXML(body, xmlCharset(contentType), v)
+		}
+		return codec.Decode(body, v)
+	})
+}
+
+// DecodeXML attempts to decode the response body as XML into the provided
+// interface `v`. The body is transcoded from the charset named by the
+// response's `Content-Type` or, lacking that, by the XML declaration;
+// ISO-8859-1 is supported in addition to UTF-8. Of the provided options, only
+// `RequireContentType` applies; the others configure JSON decoding only. If
+// there was an error anywhere in the chain, it is returned. As long as an HTTP
+// response was generated, it is returned. However, note that this method reads
+// and closes the response body. This method terminates a call chain.
+func (r *Result) DecodeXML(v interface{}, opts ...DecodeOption) (*http.Response, error) {
+	return r.decodeWith(v, opts, func(body io.Reader, _ decodeOptions) error {
+		return decodeXML(body, xmlCharset(r.response.Header.Get("Content-Type")), v)
+	})
+}
+
+// DecodeForm parses a URL-encoded form response body. If there was an error
+// anywhere in the chain, it is returned. As long as an HTTP response was
+// generated, it is returned. However, note that this method reads and closes
+// the response body. This method terminates a call chain.
+func (r *Result) DecodeForm() (*http.Response, url.Values, error) {
+	var values url.Values
+	response, err := r.decodeWith(&values, nil, func(body io.Reader, _ decodeOptions) error {
+		return formCodec{}.Decode(body, &values)
+	})
+	if err != nil {
+		return response, nil, err
+	}
+	return response, values, nil
+}
+
+// decodeWith invokes the provided decode function upon the response body,
+// after checking for errors anywhere in the chain and verifying the response
+// per the client's options and the provided options, in that order
+func (r *Result) decodeWith(v interface{}, opts []DecodeOption, decode func(body io.Reader, o decodeOptions) error) (*http.Response, error) {
 	if r.err != nil {
+		r.closeResponse()
 		return r.response, r.err
 	}
 
@@ -315,7 +1135,13 @@ func (r *Result) DecodeJSON(v interface{}) (*http.Response, error) {
 		return r.response, fmt.Errorf("decode destination was nil for '%s %s'", r.request.method, r.request.u)
 	}
 
-	err := json.NewDecoder(r.response.Body).Decode(v)
+	o := newDecodeOptions(r.request.decodeOptions, opts)
+	err := o.checkContentType(r.response)
+	if err != nil {
+		return r.response, fmt.Errorf("failed to decode the response body for '%s %s': %w", r.request.method, r.request.u, err)
+	}
+
+	err = decode(r.response.Body, o)
 	if err != nil {
 		return r.response, fmt.Errorf("failed to decode the response body for '%s %s': %w", r.request.method, r.request.u, err)
 	}
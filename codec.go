@@ -0,0 +1,110 @@
+package rhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Codec encodes and decodes HTTP bodies of a particular content type
+type Codec interface {
+	// ContentType returns the content type of the bodies handled by the codec,
+	// e.g. `application/json`. It is used as the `Content-Type` header of
+	// encoded request bodies and its media type identifies the codec.
+	ContentType() string
+
+	// Encode writes the encoding of `v` to `w`
+	Encode(w io.Writer, v interface{}) error
+
+	// Decode reads the encoding of a value from `r` and stores it in `v`
+	Decode(r io.Reader, v interface{}) error
+}
+
+// jsonCodec is the `Codec` for `application/json`, backed by `encoding/json`
+type jsonCodec struct{}
+
+var _ Codec = jsonCodec{}
+
+// ContentType returns `application/json`
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+// Encode writes the JSON encoding of `v` to `w`
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode reads the JSON encoding of a value from `r` and stores it in `v`
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// defaultCodecs are the codecs registered on every `Client`, indexed by media
+// type
+var defaultCodecs = map[string]Codec{
+	"application/json": jsonCodec{},
+	"application/xml":  xmlCodec{},
+	"text/xml":         xmlCodec{},
+	FormContentType:    formCodec{},
+}
+
+// WithCodec registers the provided codec with the client, for use by
+// `Request.Encode` and `Result.Decode` of every request subsequently
+// initialized by the client. It replaces any codec previously registered for
+// the same media type, including the default `application/json` codec.
+func (c *Client) WithCodec(codec Codec) *Client {
+	mediaType := codecMediaType(codec.ContentType())
+
+	// copy on write, since requests share the client's registry
+	codecs := make(map[string]Codec, len(c.codecs)+1)
+	for key, value := range c.registry() {
+		codecs[key] = value
+	}
+	codecs[mediaType] = codec
+	c.codecs = codecs
+
+	return c
+}
+
+// registry returns the client's codecs, indexed by media type
+func (c *Client) registry() map[string]Codec {
+	if c.codecs == nil {
+		return defaultCodecs
+	}
+	return c.codecs
+}
+
+// codecMediaType extracts the lowercase media type from a content type,
+// falling back to the trimmed content type itself if it cannot be parsed
+func codecMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// lookupCodec finds the codec for the provided content type in the registry.
+// Structured syntax suffixes are honored, e.g. `application/problem+json` is
+// handled by the `application/json` codec unless it has its own codec.
+func lookupCodec(codecs map[string]Codec, contentType string) (Codec, error) {
+	mediaType := codecMediaType(contentType)
+	if mediaType == "" {
+		return nil, fmt.Errorf("no content type")
+	}
+
+	if codec, ok := codecs[mediaType]; ok {
+		return codec, nil
+	}
+
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		if codec, ok := codecs["application/"+mediaType[i+1:]]; ok {
+			return codec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no codec registered for content type '%s'", contentType)
+}
@@ -0,0 +1,266 @@
+package rhttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// textCodec is a trivial codec for `text/plain` bodies that encodes values
+// with `fmt` and decodes them into strings
+type textCodec struct{}
+
+func (textCodec) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (textCodec) Encode(w io.Writer, v interface{}) error {
+	_, err := fmt.Fprint(w, v)
+	return err
+}
+
+func (textCodec) Decode(r io.Reader, v interface{}) error {
+	dst, ok := v.(*string)
+	if !ok {
+		return fmt.Errorf("cannot decode text into '%T'", v)
+	}
+
+	buf, err := io.ReadAll(r)
+	*dst = string(buf)
+	return err
+}
+
+func checkRequestHeader(key, expectedValue string) requestCheckFn {
+	return func(req *http.Request, t *testing.T) {
+		if diff := cmp.Diff(expectedValue, req.Header.Get(key)); diff != "" {
+			t.Errorf("Actual header '%s' diverges from expectation (-want +got): %s", key, diff)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tcs := []struct {
+		name            string
+		requestFn       requestFn
+		requestCheckFns []requestCheckFn
+		expectedErr     error
+	}{
+		{
+			name: "JSON",
+			requestFn: func(r *Request) *Request {
+				return r.Encode(payload{1, "a"}, "application/json")
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestBody("{\"Val1\":1,\"Val2\":\"a\"}\n"),
+				checkRequestHeader("Content-Type", "application/json"),
+			},
+		},
+		{
+			name: "EncodeJSON",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeJSON(payload{1, "a"})
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestBody("{\"Val1\":1,\"Val2\":\"a\"}\n"),
+				checkRequestHeader("Content-Type", "application/json"),
+			},
+		},
+		{
+			name: "EncodeJSONContentTypeOverridden",
+			requestFn: func(r *Request) *Request {
+				return r.SetHeader("Content-Type", "application/merge-patch+json").EncodeJSON(payload{1, "a"})
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestHeader("Content-Type", "application/merge-patch+json"),
+			},
+		},
+		{
+			name: "RegisteredCodec",
+			requestFn: func(r *Request) *Request {
+				return r.Encode(42, "text/plain")
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestBody("42"),
+				checkRequestHeader("Content-Type", "text/plain"),
+			},
+		},
+		{
+			name: "ContentTypeOverridden",
+			requestFn: func(r *Request) *Request {
+				return r.Encode(payload{1, "a"}, "application/json").SetHeader("Content-Type", "application/vnd.test+json")
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestHeader("Content-Type", "application/vnd.test+json"),
+			},
+		},
+		{
+			name: "UnregisteredCodec",
+			requestFn: func(r *Request) *Request {
+				return r.Encode(payload{1, "a"}, "application/msgpack")
+			},
+			expectedErr: cmpopts.AnyError,
+		},
+		{
+			name: "EncodingFailure",
+			requestFn: func(r *Request) *Request {
+				return r.Encode(&unencodablePayload{}, "application/json")
+			},
+			expectedErr: errUnencodable,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				requestCheckFns: tc.requestCheckFns,
+				t:               t,
+				doFn:            respondWith(http.StatusOK, nil, nil),
+			}).WithCodec(textCodec{})
+
+			_, err := tc.requestFn(c.POST(&url.URL{Scheme: "http", Host: "test.test.test"})).Do().Response()
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tcs := []struct {
+		name        string
+		contentType string
+		body        string
+		v           func() interface{}
+		expected    interface{}
+		expectedErr error
+	}{
+		{
+			name:        "JSON",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"Val1":1,"Val2":"a"}`,
+			v:           func() interface{} { return &payload{} },
+			expected:    &payload{1, "a"},
+		},
+		{
+			name:        "StructuredSyntaxSuffix",
+			contentType: "application/vnd.test+json",
+			body:        `{"Val1":1,"Val2":"a"}`,
+			v:           func() interface{} { return &payload{} },
+			expected:    &payload{1, "a"},
+		},
+		{
+			name:        "RegisteredCodec",
+			contentType: "Text/Plain",
+			body:        "hello",
+			v:           func() interface{} { return new(string) },
+			expected:    func() *string { s := "hello"; return &s }(),
+		},
+		{
+			name:        "UnregisteredCodec",
+			contentType: "text/html",
+			body:        "<html></html>",
+			v:           func() interface{} { return new(string) },
+			expected:    new(string),
+			expectedErr: cmpopts.AnyError,
+		},
+		{
+			name:        "NoContentType",
+			body:        "hello",
+			v:           func() interface{} { return new(string) },
+			expected:    new(string),
+			expectedErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					resp, err := respondWith(http.StatusOK, []byte(tc.body), nil)(req)
+					resp.Header = http.Header{}
+					if tc.contentType != "" {
+						resp.Header.Set("Content-Type", tc.contentType)
+					}
+					return resp, err
+				},
+			}).WithCodec(textCodec{})
+
+			v := tc.v()
+			_, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().Decode(v)
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, v); diff != "" {
+				t.Errorf("Actual value diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+
+	t.Run("DefaultRegistryUnaffected", func(t *testing.T) {
+		NewClient(nil).WithCodec(textCodec{})
+		if _, err := lookupCodec(defaultCodecs, "text/plain"); err == nil {
+			t.Errorf("Did not expect registering a codec on a client to affect the defaults")
+		}
+		if _, ok := defaultCodecs["application/json"]; !ok {
+			t.Errorf("Expected the default registry to contain a JSON codec")
+		}
+	})
+}
+
+func TestEncodeOverridesDefaultContentType(t *testing.T) {
+	tcs := []struct {
+		name                string
+		requestFn           requestFn
+		expectedContentType string
+	}{
+		{
+			name: "EncodeForm",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeForm(url.Values{"a": {"1"}})
+			},
+			expectedContentType: FormContentType,
+		},
+		{
+			name: "EncodeXML",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeXML(xmlPayload{Val1: 1})
+			},
+			expectedContentType: "application/xml; charset=utf-8",
+		},
+		{
+			name: "RequestHeaderOverridesEncoder",
+			requestFn: func(r *Request) *Request {
+				return r.WithHeader("Content-Type", "text/xml").EncodeXML(xmlPayload{Val1: 1})
+			},
+			expectedContentType: "text/xml",
+		},
+		{
+			name: "NoBody",
+			requestFn: func(r *Request) *Request {
+				return r
+			},
+			expectedContentType: "application/json",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				requestCheckFns: []requestCheckFn{checkRequestHeader("Content-Type", tc.expectedContentType)},
+				t:               t,
+				doFn:            respondWith(http.StatusOK, nil, nil),
+			}).WithDefaultHeader("Content-Type", "application/json")
+
+			_, err := tc.requestFn(c.POST(&url.URL{Scheme: "http", Host: "test.test.test"})).Do().Response()
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
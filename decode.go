@@ -0,0 +1,160 @@
+package rhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrUnexpectedContentType is returned by the decoding methods of `*Result` if
+// the response's `Content-Type` is not among those required with
+// `RequireContentType`, e.g. when a proxy responded with an HTML error page
+var ErrUnexpectedContentType = errors.New("unexpected content type")
+
+// DecodeOption configures how the decoding methods of `*Result` decode a
+// response body
+type DecodeOption func(*decodeOptions)
+
+// decodeOptions is the configuration assembled from `DecodeOption`s
+type decodeOptions struct {
+	contentTypes          []string
+	disallowUnknownFields bool
+	useNumber             bool
+	requireSingleValue    bool
+}
+
+// WithDecodeOptions sets options that apply whenever the response of a request
+// subsequently initialized by the client is decoded. The options passed to an
+// individual decoding method are applied after them, so they may override
+// them.
+func (c *Client) WithDecodeOptions(opts ...DecodeOption) *Client {
+	c.decodeOptions = append(append([]DecodeOption(nil), c.decodeOptions...), opts...)
+	return c
+}
+
+// RequireContentType verifies, before decoding, that the response's
+// `Content-Type` is one of the provided media types. A media type also matches
+// structured syntax suffixes, e.g. `application/json` matches
+// `application/problem+json`, and `type/*` matches any subtype. Otherwise,
+// decoding fails with `ErrUnexpectedContentType`, along with a snippet of the
+// response body.
+func RequireContentType(mediaTypes ...string) DecodeOption {
+	return func(o *decodeOptions) {
+		o.contentTypes = append(o.contentTypes, mediaTypes...)
+	}
+}
+
+// DisallowUnknownFields makes JSON decoding fail if an object has a key that
+// does not match an exported field of the destination struct, as with
+// `json.Decoder.DisallowUnknownFields`
+func DisallowUnknownFields(enabled bool) DecodeOption {
+	return func(o *decodeOptions) {
+		o.disallowUnknownFields = enabled
+	}
+}
+
+// UseNumber makes JSON decoding unmarshal a number into an `interface{}` as a
+// `json.Number` instead of a `float64`, as with `json.Decoder.UseNumber`
+func UseNumber(enabled bool) DecodeOption {
+	return func(o *decodeOptions) {
+		o.useNumber = enabled
+	}
+}
+
+// RequireSingleValue makes JSON decoding fail if the response body contains
+// anything but whitespace after the first JSON value
+func RequireSingleValue(enabled bool) DecodeOption {
+	return func(o *decodeOptions) {
+		o.requireSingleValue = enabled
+	}
+}
+
+// newDecodeOptions assembles the configuration from the provided options, in
+// order
+func newDecodeOptions(groups ...[]DecodeOption) decodeOptions {
+	var o decodeOptions
+	for _, opts := range groups {
+		for _, opt := range opts {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// newJSONDecoder vends a `*json.Decoder` reading from `r`, as configured
+func (o decodeOptions) newJSONDecoder(r io.Reader) *json.Decoder {
+	dec := json.NewDecoder(r)
+	if o.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if o.useNumber {
+		dec.UseNumber()
+	}
+	return dec
+}
+
+// decodeJSON reads the JSON encoding of a value from `r` and stores it in `v`,
+// as configured
+func (o decodeOptions) decodeJSON(r io.Reader, v interface{}) error {
+	dec := o.newJSONDecoder(r)
+	err := dec.Decode(v)
+	if err != nil {
+		return err
+	}
+
+	if o.requireSingleValue {
+		_, err = dec.Token()
+		if err == nil {
+			return errors.New("unexpected JSON value after the first")
+		}
+		if err != io.EOF {
+			return fmt.Errorf("unexpected data after the first JSON value: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkContentType verifies the content type of the response, if required,
+// reading a snippet of its body into the error if it is unexpected
+func (o decodeOptions) checkContentType(resp *http.Response) error {
+	if len(o.contentTypes) == 0 {
+		return nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType := codecMediaType(contentType)
+	for _, required := range o.contentTypes {
+		if mediaTypeMatches(codecMediaType(required), mediaType) {
+			return nil
+		}
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorSnippetBytes))
+	return fmt.Errorf("%w '%s', expected '%s': %q", ErrUnexpectedContentType, contentType, strings.Join(o.contentTypes, "' or '"), strings.ToValidUTF8(string(snippet), ""))
+}
+
+// mediaTypeMatches reports whether the media type satisfies the required one
+func mediaTypeMatches(required, mediaType string) bool {
+	if mediaType == "" {
+		return false
+	}
+
+	if required == mediaType || required == "*/*" {
+		return true
+	}
+
+	if strings.HasSuffix(required, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(required, "*"))
+	}
+
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		topLevel := mediaType[:strings.Index(mediaType, "/")+1]
+		return required == topLevel+mediaType[i+1:]
+	}
+
+	return false
+}
@@ -0,0 +1,260 @@
+package rhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRequireContentType(t *testing.T) {
+	tcs := []struct {
+		name        string
+		contentType string
+		body        string
+		required    []string
+		expected    *payload
+		expectedErr error
+	}{
+		{
+			name:        "NotRequired",
+			contentType: "text/plain",
+			body:        `{"Val1":1,"Val2":"a"}`,
+			expected:    &payload{1, "a"},
+		},
+		{
+			name:        "Match",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"Val1":1,"Val2":"a"}`,
+			required:    []string{"application/json"},
+			expected:    &payload{1, "a"},
+		},
+		{
+			name:        "CaseInsensitive",
+			contentType: "Application/JSON",
+			body:        `{"Val1":1,"Val2":"a"}`,
+			required:    []string{"application/json"},
+			expected:    &payload{1, "a"},
+		},
+		{
+			name:        "StructuredSyntaxSuffix",
+			contentType: "application/vnd.test+json",
+			body:        `{"Val1":1,"Val2":"a"}`,
+			required:    []string{"application/json"},
+			expected:    &payload{1, "a"},
+		},
+		{
+			name:        "Wildcard",
+			contentType: "text/x-json",
+			body:        `{"Val1":1,"Val2":"a"}`,
+			required:    []string{"application/json", "text/*"},
+			expected:    &payload{1, "a"},
+		},
+		{
+			name:        "HTML",
+			contentType: "text/html; charset=utf-8",
+			body:        "<html><body>502 Bad Gateway</body></html>",
+			required:    []string{"application/json"},
+			expected:    &payload{},
+			expectedErr: ErrUnexpectedContentType,
+		},
+		{
+			name:        "SuffixOfOtherTopLevelType",
+			contentType: "text/vnd.test+json",
+			body:        `{"Val1":1,"Val2":"a"}`,
+			required:    []string{"application/json"},
+			expected:    &payload{},
+			expectedErr: ErrUnexpectedContentType,
+		},
+		{
+			name:        "NoContentType",
+			body:        `{"Val1":1,"Val2":"a"}`,
+			required:    []string{"application/json"},
+			expected:    &payload{},
+			expectedErr: ErrUnexpectedContentType,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					resp, err := respondWith(http.StatusOK, []byte(tc.body), nil)(req)
+					resp.Header = http.Header{}
+					if tc.contentType != "" {
+						resp.Header.Set("Content-Type", tc.contentType)
+					}
+					return resp, err
+				},
+			})
+
+			var opts []DecodeOption
+			if tc.required != nil {
+				opts = append(opts, RequireContentType(tc.required...))
+			}
+
+			v := &payload{}
+			_, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().DecodeJSON(v, opts...)
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, v); diff != "" {
+				t.Errorf("Actual value diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+
+	t.Run("ErrorContainsBodySnippet", func(t *testing.T) {
+		c := NewClient(&mock{
+			t: t,
+			doFn: func(req *http.Request) (*http.Response, error) {
+				resp, err := respondWith(http.StatusOK, []byte("<html>502 Bad Gateway</html>"), nil)(req)
+				resp.Header = http.Header{"Content-Type": {"text/html"}}
+				return resp, err
+			},
+		})
+
+		_, _, err := JSON[payload](c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do(), RequireContentType("application/json"))
+		if err == nil || !strings.Contains(err.Error(), "502 Bad Gateway") {
+			t.Errorf("Expected the error to contain a snippet of the body, got: %v", err)
+		}
+	})
+}
+
+func TestStrictJSON(t *testing.T) {
+	tcs := []struct {
+		name          string
+		clientOptions []DecodeOption
+		callOptions   []DecodeOption
+		body          string
+		v             func() interface{}
+		expected      interface{}
+		expectedErr   error
+	}{
+		{
+			name:     "UnknownFieldsAllowedByDefault",
+			body:     `{"Val1":1,"Val2":"a","Val3":true}`,
+			v:        func() interface{} { return &payload{} },
+			expected: &payload{1, "a"},
+		},
+		{
+			name:        "DisallowUnknownFields",
+			callOptions: []DecodeOption{DisallowUnknownFields(true)},
+			body:        `{"Val1":1,"Val2":"a","Val3":true}`,
+			v:           func() interface{} { return &payload{} },
+			expected:    &payload{1, "a"},
+			expectedErr: cmpopts.AnyError,
+		},
+		{
+			name:          "DisallowUnknownFieldsPerClient",
+			clientOptions: []DecodeOption{DisallowUnknownFields(true)},
+			body:          `{"Val1":1,"Val2":"a","Val3":true}`,
+			v:             func() interface{} { return &payload{} },
+			expected:      &payload{1, "a"},
+			expectedErr:   cmpopts.AnyError,
+		},
+		{
+			name:          "CallOverridesClient",
+			clientOptions: []DecodeOption{DisallowUnknownFields(true)},
+			callOptions:   []DecodeOption{DisallowUnknownFields(false)},
+			body:          `{"Val1":1,"Val2":"a","Val3":true}`,
+			v:             func() interface{} { return &payload{} },
+			expected:      &payload{1, "a"},
+		},
+		{
+			name: "Float64ByDefault",
+			body: `{"n":12345678901234567890}`,
+			v:    func() interface{} { return new(interface{}) },
+			expected: func() *interface{} {
+				var v interface{} = map[string]interface{}{"n": 12345678901234567890.0}
+				return &v
+			}(),
+		},
+		{
+			name:        "UseNumber",
+			callOptions: []DecodeOption{UseNumber(true)},
+			body:        `{"n":12345678901234567890}`,
+			v:           func() interface{} { return new(interface{}) },
+			expected: func() *interface{} {
+				var v interface{} = map[string]interface{}{"n": json.Number("12345678901234567890")}
+				return &v
+			}(),
+		},
+		{
+			name:     "TrailingDataIgnoredByDefault",
+			body:     `{"Val1":1,"Val2":"a"} garbage`,
+			v:        func() interface{} { return &payload{} },
+			expected: &payload{1, "a"},
+		},
+		{
+			name:        "RequireSingleValue",
+			callOptions: []DecodeOption{RequireSingleValue(true)},
+			body:        "{\"Val1\":1,\"Val2\":\"a\"}\n\t ",
+			v:           func() interface{} { return &payload{} },
+			expected:    &payload{1, "a"},
+		},
+		{
+			name:        "RequireSingleValueWithTrailingGarbage",
+			callOptions: []DecodeOption{RequireSingleValue(true)},
+			body:        `{"Val1":1,"Val2":"a"} garbage`,
+			v:           func() interface{} { return &payload{} },
+			expected:    &payload{1, "a"},
+			expectedErr: cmpopts.AnyError,
+		},
+		{
+			name:        "RequireSingleValueWithTrailingValue",
+			callOptions: []DecodeOption{RequireSingleValue(true)},
+			body:        `{"Val1":1,"Val2":"a"}{"Val1":2}`,
+			v:           func() interface{} { return &payload{} },
+			expected:    &payload{1, "a"},
+			expectedErr: cmpopts.AnyError,
+		},
+		{
+			name:        "RequireSingleValueWithTrailingDelimiter",
+			callOptions: []DecodeOption{RequireSingleValue(true)},
+			body:        `{"Val1":1,"Val2":"a"}}`,
+			v:           func() interface{} { return &payload{} },
+			expected:    &payload{1, "a"},
+			expectedErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				t:    t,
+				doFn: respondWith(http.StatusOK, []byte(tc.body), nil),
+			}).WithDecodeOptions(tc.clientOptions...)
+
+			v := tc.v()
+			_, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().DecodeJSON(v, tc.callOptions...)
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, v); diff != "" {
+				t.Errorf("Actual value diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+
+	t.Run("Codec", func(t *testing.T) {
+		c := NewClient(&mock{
+			t: t,
+			doFn: func(req *http.Request) (*http.Response, error) {
+				resp, err := respondWith(http.StatusOK, []byte(`{"Val1":1,"Val3":true}`), nil)(req)
+				resp.Header = http.Header{"Content-Type": {"application/json"}}
+				return resp, err
+			},
+		}).WithDecodeOptions(DisallowUnknownFields(true))
+
+		_, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().Decode(&payload{})
+		if err == nil {
+			t.Errorf("Expected the client's options to apply to the JSON codec")
+		}
+	})
+}
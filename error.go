@@ -1,27 +1,145 @@
 package rhttp
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Package-defined generic errors for common HTTP 4xx- & 5xx-series errors
+// Package-defined generic errors for every registered HTTP 4xx- & 5xx-series
+// status code
 var (
-	ErrBadRequest         = newGenericError(http.StatusBadRequest)
-	ErrUnauthorized       = newGenericError(http.StatusUnauthorized)
-	ErrForbidden          = newGenericError(http.StatusForbidden)
-	ErrNotFound           = newGenericError(http.StatusNotFound)
-	ErrConflict           = newGenericError(http.StatusConflict)
-	ErrInternalServer     = newGenericError(http.StatusInternalServerError)
-	ErrServiceUnavailable = newGenericError(http.StatusServiceUnavailable)
-	ErrNotImplemented     = newGenericError(http.StatusNotImplemented)
+	ErrBadRequest                   = newGenericError(http.StatusBadRequest)
+	ErrUnauthorized                 = newGenericError(http.StatusUnauthorized)
+	ErrPaymentRequired              = newGenericError(http.StatusPaymentRequired)
+	ErrForbidden                    = newGenericError(http.StatusForbidden)
+	ErrNotFound                     = newGenericError(http.StatusNotFound)
+	ErrMethodNotAllowed             = newGenericError(http.StatusMethodNotAllowed)
+	ErrNotAcceptable                = newGenericError(http.StatusNotAcceptable)
+	ErrProxyAuthRequired            = newGenericError(http.StatusProxyAuthRequired)
+	ErrRequestTimeout               = newGenericError(http.StatusRequestTimeout)
+	ErrConflict                     = newGenericError(http.StatusConflict)
+	ErrGone                         = newGenericError(http.StatusGone)
+	ErrLengthRequired               = newGenericError(http.StatusLengthRequired)
+	ErrPreconditionFailed           = newGenericError(http.StatusPreconditionFailed)
+	ErrRequestEntityTooLarge        = newGenericError(http.StatusRequestEntityTooLarge)
+	ErrRequestURITooLong            = newGenericError(http.StatusRequestURITooLong)
+	ErrUnsupportedMediaType         = newGenericError(http.StatusUnsupportedMediaType)
+	ErrRequestedRangeNotSatisfiable = newGenericError(http.StatusRequestedRangeNotSatisfiable)
+	ErrExpectationFailed            = newGenericError(http.StatusExpectationFailed)
+	ErrTeapot                       = newGenericError(http.StatusTeapot)
+	ErrMisdirectedRequest           = newGenericError(http.StatusMisdirectedRequest)
+	ErrUnprocessableEntity          = newGenericError(http.StatusUnprocessableEntity)
+	ErrLocked                       = newGenericError(http.StatusLocked)
+	ErrFailedDependency             = newGenericError(http.StatusFailedDependency)
+	ErrTooEarly                     = newGenericError(http.StatusTooEarly)
+	ErrUpgradeRequired              = newGenericError(http.StatusUpgradeRequired)
+	ErrPreconditionRequired         = newGenericError(http.StatusPreconditionRequired)
+	ErrTooManyRequests              = newGenericError(http.StatusTooManyRequests)
+	ErrRequestHeaderFieldsTooLarge  = newGenericError(http.StatusRequestHeaderFieldsTooLarge)
+	ErrUnavailableForLegalReasons   = newGenericError(http.StatusUnavailableForLegalReasons)
+
+	ErrInternalServer                = newGenericError(http.StatusInternalServerError)
+	ErrNotImplemented                = newGenericError(http.StatusNotImplemented)
+	ErrBadGateway                    = newGenericError(http.StatusBadGateway)
+	ErrServiceUnavailable            = newGenericError(http.StatusServiceUnavailable)
+	ErrGatewayTimeout                = newGenericError(http.StatusGatewayTimeout)
+	ErrHTTPVersionNotSupported       = newGenericError(http.StatusHTTPVersionNotSupported)
+	ErrVariantAlsoNegotiates         = newGenericError(http.StatusVariantAlsoNegotiates)
+	ErrInsufficientStorage           = newGenericError(http.StatusInsufficientStorage)
+	ErrLoopDetected                  = newGenericError(http.StatusLoopDetected)
+	ErrNotExtended                   = newGenericError(http.StatusNotExtended)
+	ErrNetworkAuthenticationRequired = newGenericError(http.StatusNetworkAuthenticationRequired)
 )
 
+// genericErrors indexes the package-defined generic errors by status code
+var genericErrors = func() map[int]*Error {
+	errs := make(map[int]*Error)
+	for _, err := range []*Error{
+		ErrBadRequest, ErrUnauthorized, ErrPaymentRequired, ErrForbidden,
+		ErrNotFound, ErrMethodNotAllowed, ErrNotAcceptable,
+		ErrProxyAuthRequired, ErrRequestTimeout, ErrConflict, ErrGone,
+		ErrLengthRequired, ErrPreconditionFailed, ErrRequestEntityTooLarge,
+		ErrRequestURITooLong, ErrUnsupportedMediaType,
+		ErrRequestedRangeNotSatisfiable, ErrExpectationFailed, ErrTeapot,
+		ErrMisdirectedRequest, ErrUnprocessableEntity, ErrLocked,
+		ErrFailedDependency, ErrTooEarly, ErrUpgradeRequired,
+		ErrPreconditionRequired, ErrTooManyRequests,
+		ErrRequestHeaderFieldsTooLarge, ErrUnavailableForLegalReasons,
+		ErrInternalServer, ErrNotImplemented, ErrBadGateway,
+		ErrServiceUnavailable, ErrGatewayTimeout, ErrHTTPVersionNotSupported,
+		ErrVariantAlsoNegotiates, ErrInsufficientStorage, ErrLoopDetected,
+		ErrNotExtended, ErrNetworkAuthenticationRequired,
+	} {
+		errs[err.StatusCode] = err
+	}
+	return errs
+}()
+
+// retryableStatusCodes are the status codes of errors that are typically
+// transient, such that the request may succeed if retried
+var retryableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// ErrorFromStatus returns the package-defined generic error for the provided
+// 4xx- or 5xx-series status code. For unregistered codes in that range, it
+// creates a new generic error. For any other status code, it returns nil.
+func ErrorFromStatus(statusCode int) *Error {
+	if err, ok := genericErrors[statusCode]; ok {
+		return err
+	}
+
+	if statusCode < 400 || statusCode > 599 {
+		return nil
+	}
+
+	return newGenericError(statusCode)
+}
+
 // Error represents the combination of an HTTP status code and message. It
-// meets the standard golang Error interface
+// meets the standard golang Error interface. It may also carry the members of
+// a problem details document (RFC 9457), in which form it is encoded to and
+// decoded from JSON.
 type Error struct {
 	StatusCode int
 	Message    string
+
+	// Type, Title, Detail & Instance are the standard members of a problem
+	// details document, while Extensions holds any additional members
+	Type       string
+	Title      string
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+
+	// Header holds HTTP headers associated with the error, e.g. a
+	// `Retry-After` header to send alongside it (see `WriteError`)
+	Header http.Header
+
+	// Cause optionally holds the underlying error, which is returned by
+	// `Unwrap`
+	Cause error
+
+	// Payload optionally holds the decoded body of the response that produced
+	// the error (see `Result.DecodeJSONOrError`)
+	Payload interface{}
+
+	// Method, URL, ResponseHeader & Body describe the request and response
+	// that produced the error, if it was produced by a `Client`. The URL is
+	// redacted of any password and the Body is a snippet of at most 1 KiB.
+	Method         string
+	URL            string
+	ResponseHeader http.Header
+	Body           string
 }
 
 var _ error = &Error{}
@@ -48,14 +166,103 @@ func (e *Error) New(message string) *Error {
 }
 
 // Newf creates an *http.Error that with the same status code and the provided
-// messages, using the golang convention for format strings and arguments
+// messages, using the golang convention for format strings and arguments. As
+// with `fmt.Errorf`, an error argument for a `%w` verb is wrapped, i.e. it
+// becomes the `Cause`.
 func (e *Error) Newf(format string, args ...interface{}) *Error {
-	return NewError(e.StatusCode, fmt.Sprintf(format, args...))
+	wrapped := fmt.Errorf(format, args...)
+
+	err := NewError(e.StatusCode, wrapped.Error())
+	if cause := errors.Unwrap(wrapped); cause != nil {
+		err.Cause = cause
+	} else if _, ok := wrapped.(interface{ Unwrap() []error }); ok {
+		err.Cause = wrapped
+	}
+
+	return err
+}
+
+// Wrap creates an *http.Error with the same status code and message that wraps
+// the provided error as its `Cause`. Since the message is unchanged, this is
+// useful to attach internal details to an error without exposing them in the
+// message, e.g. `ErrInternalServer.Wrap(err)`.
+func (e *Error) Wrap(cause error) *Error {
+	err := NewError(e.StatusCode, e.Message)
+	err.Cause = cause
+
+	return err
+}
+
+// Unwrap returns the underlying error, if any
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// WithHeader creates a copy of the *http.Error with the provided header added
+// to its `Header`. The original error is not modified, so this is safe to use
+// with the package-defined errors, e.g. `ErrUnauthorized.WithHeader(...)`.
+func (e *Error) WithHeader(key, value string) *Error {
+	dup := e.withClonedHeader()
+	dup.Header.Add(key, value)
+
+	return dup
 }
 
-// Error returns the underlying error message
+// WithRetryAfter creates a copy of the *http.Error with a `Retry-After` header
+// advising the client to wait for the provided duration (rounded up to whole
+// seconds) before retrying
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	seconds := int64((d + time.Second - 1) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	dup := e.withClonedHeader()
+	dup.Header.Set("Retry-After", strconv.FormatInt(seconds, 10))
+
+	return dup
+}
+
+// RetryAfter returns the delay advised by the error's `Retry-After` header,
+// whether set on the error itself or received in the response that produced
+// it. The header may specify either a number of seconds or an HTTP-date. The
+// boolean is false if there is no such header or it is malformed.
+func (e *Error) RetryAfter() (time.Duration, bool) {
+	value := e.Header.Get("Retry-After")
+	if value == "" {
+		value = e.ResponseHeader.Get("Retry-After")
+	}
+
+	return parseRetryAfter(value, time.Now())
+}
+
+// withClonedHeader creates a copy of the *http.Error with a non-nil copy of its
+// `Header`, which may then be modified independently of the original
+func (e *Error) withClonedHeader() *Error {
+	dup := *e
+	dup.Header = e.Header.Clone()
+	if dup.Header == nil {
+		dup.Header = make(http.Header)
+	}
+
+	return &dup
+}
+
+// Error returns the underlying error message, prefixed by the method and url
+// of the request that produced it and suffixed by its cause, if either is
+// known and not already part of the message
 func (e *Error) Error() string {
-	return fmt.Sprintf("(%d) %s", e.StatusCode, e.Message)
+	msg := fmt.Sprintf("(%d) %s", e.StatusCode, e.Message)
+
+	if e.Cause != nil && !strings.Contains(e.Message, e.Cause.Error()) {
+		msg = fmt.Sprintf("%s: %v", msg, e.Cause)
+	}
+
+	if e.Method != "" || e.URL != "" {
+		msg = fmt.Sprintf("%s %s: %s", e.Method, e.URL, msg)
+	}
+
+	return msg
 }
 
 // Is returns true if and only if the provided error has the same status code
@@ -64,7 +271,37 @@ func (e *Error) Is(err error) bool {
 	return castSuccess && inst != nil && inst.StatusCode == e.StatusCode
 }
 
+// IsClientError returns true if and only if the error has a 4xx-series status
+// code
+func (e *Error) IsClientError() bool {
+	return e.StatusCode >= 400 && e.StatusCode <= 499
+}
+
+// IsServerError returns true if and only if the error has a 5xx-series status
+// code
+func (e *Error) IsServerError() bool {
+	return e.StatusCode >= 500 && e.StatusCode <= 599
+}
+
+// IsRetryable returns true if and only if the error has a status code that
+// indicates a typically transient failure, such that the request may succeed
+// if retried, i.e. 408, 429, 500, 502, 503 or 504
+func (e *Error) IsRetryable() bool {
+	for _, statusCode := range retryableStatusCodes {
+		if e.StatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
 // HasStatusCode returns true if and only if the error has the provided status code
 func (e *Error) HasStatusCode(statusCode int) bool {
 	return e.StatusCode == statusCode
 }
+
+// fromUpstream reports whether the error was produced by a `Client` for an
+// upstream response, as opposed to by server code
+func (e *Error) fromUpstream() bool {
+	return e.Method != "" || e.URL != "" || e.ResponseHeader != nil
+}
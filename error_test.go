@@ -1,7 +1,9 @@
 package rhttp
 
 import (
+	stderrors "errors"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 	"strconv"
@@ -13,14 +15,64 @@ func TestError(t *testing.T) {
 	errors := []*Error{
 		ErrBadRequest,
 		ErrUnauthorized,
+		ErrPaymentRequired,
 		ErrForbidden,
 		ErrNotFound,
+		ErrMethodNotAllowed,
+		ErrNotAcceptable,
+		ErrProxyAuthRequired,
+		ErrRequestTimeout,
 		ErrConflict,
+		ErrGone,
+		ErrLengthRequired,
+		ErrPreconditionFailed,
+		ErrRequestEntityTooLarge,
+		ErrRequestURITooLong,
+		ErrUnsupportedMediaType,
+		ErrRequestedRangeNotSatisfiable,
+		ErrExpectationFailed,
+		ErrTeapot,
+		ErrMisdirectedRequest,
+		ErrUnprocessableEntity,
+		ErrLocked,
+		ErrFailedDependency,
+		ErrTooEarly,
+		ErrUpgradeRequired,
+		ErrPreconditionRequired,
+		ErrTooManyRequests,
+		ErrRequestHeaderFieldsTooLarge,
+		ErrUnavailableForLegalReasons,
 		ErrInternalServer,
-		ErrServiceUnavailable,
 		ErrNotImplemented,
+		ErrBadGateway,
+		ErrServiceUnavailable,
+		ErrGatewayTimeout,
+		ErrHTTPVersionNotSupported,
+		ErrVariantAlsoNegotiates,
+		ErrInsufficientStorage,
+		ErrLoopDetected,
+		ErrNotExtended,
+		ErrNetworkAuthenticationRequired,
 	}
 
+	t.Run("PackageErrorsCoverRegisteredStatusCodes", func(_ *testing.T) {
+		for statusCode := 400; statusCode <= 599; statusCode++ {
+			if http.StatusText(statusCode) == "" {
+				continue
+			}
+
+			found := false
+			for _, err := range errors {
+				if err.StatusCode == statusCode {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected a package error for status code '%d'", statusCode)
+			}
+		}
+	})
+
 	t.Run("PackageErrorsUseGenericMessages", func(_ *testing.T) {
 		for _, err := range errors {
 			expected := http.StatusText(err.StatusCode)
@@ -76,6 +128,59 @@ func TestError(t *testing.T) {
 		}
 	})
 
+	t.Run("NewfWraps", func(_ *testing.T) {
+		cause := io.ErrUnexpectedEOF
+		for _, err := range errors {
+			output := err.Newf("failed to read: %w", cause)
+
+			if output.Cause != cause {
+				t.Errorf("Expected cause '%v' but got cause '%v'", cause, output.Cause)
+			}
+			if !stderrors.Is(output, cause) || !stderrors.Is(output, err) {
+				t.Errorf("Expected error '%v' to match both '%v' and '%v'", output, cause, err)
+			}
+			if output.Error() != fmt.Sprintf("(%d) failed to read: %v", err.StatusCode, cause) {
+				t.Errorf("Did not expect the cause to be repeated in error string '%s'", output.Error())
+			}
+		}
+	})
+
+	t.Run("Wrap", func(_ *testing.T) {
+		cause := fmt.Errorf("connection refused")
+		for _, err := range errors {
+			output := err.Wrap(cause)
+
+			expected := &Error{
+				StatusCode: err.StatusCode,
+				Message:    err.Message,
+				Cause:      cause,
+			}
+			if !reflect.DeepEqual(output, expected) {
+				t.Errorf("Expected error '%v' but got error '%v'", expected, output)
+			}
+			if output.Unwrap() != cause {
+				t.Errorf("Expected to unwrap cause '%v' from error '%v'", cause, output)
+			}
+			if !strings.Contains(output.Error(), cause.Error()) {
+				t.Errorf("Expected error string '%s' to contain the cause '%v'", output.Error(), cause)
+			}
+		}
+	})
+
+	t.Run("ErrorIdentifiesRequest", func(_ *testing.T) {
+		err := &Error{
+			StatusCode: http.StatusNotFound,
+			Message:    "missing",
+			Method:     http.MethodGet,
+			URL:        "http://test.test.test/x",
+		}
+
+		expected := "GET http://test.test.test/x: (404) missing"
+		if output := err.Error(); output != expected {
+			t.Errorf("Expected error string '%s' but got '%s'", expected, output)
+		}
+	})
+
 	t.Run("Error", func(_ *testing.T) {
 		for _, err := range errors {
 			errStr := err.Error()
@@ -98,6 +203,51 @@ func TestError(t *testing.T) {
 		}
 	})
 
+	t.Run("ErrorFromStatus", func(_ *testing.T) {
+		for _, err := range errors {
+			output := ErrorFromStatus(err.StatusCode)
+			if output != err {
+				t.Errorf("Expected error '%v' for status code '%d' but got error '%v'", err, err.StatusCode, output)
+			}
+		}
+
+		output := ErrorFromStatus(499)
+		expected := &Error{StatusCode: 499}
+		if !reflect.DeepEqual(output, expected) {
+			t.Errorf("Expected error '%v' but got error '%v'", expected, output)
+		}
+
+		for _, statusCode := range []int{0, 200, 302, 600} {
+			if output := ErrorFromStatus(statusCode); output != nil {
+				t.Errorf("Expected no error for status code '%d' but got error '%v'", statusCode, output)
+			}
+		}
+	})
+
+	t.Run("Classification", func(_ *testing.T) {
+		retryable := map[*Error]bool{
+			ErrRequestTimeout:     true,
+			ErrTooManyRequests:    true,
+			ErrInternalServer:     true,
+			ErrBadGateway:         true,
+			ErrServiceUnavailable: true,
+			ErrGatewayTimeout:     true,
+		}
+
+		for _, err := range errors {
+			isClient := err.StatusCode < 500
+			if output := err.IsClientError(); output != isClient {
+				t.Errorf("Expected IsClientError of error '%v' to be %v", err, isClient)
+			}
+			if output := err.IsServerError(); output == isClient {
+				t.Errorf("Expected IsServerError of error '%v' to be %v", err, !isClient)
+			}
+			if output := err.IsRetryable(); output != retryable[err] {
+				t.Errorf("Expected IsRetryable of error '%v' to be %v", err, retryable[err])
+			}
+		}
+	})
+
 	t.Run("HasStatusCode", func(_ *testing.T) {
 		for i, err := range errors {
 			for j, other := range errors {
@@ -0,0 +1,55 @@
+package rhttp
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// FormContentType is the content type of URL-encoded form bodies
+const FormContentType = "application/x-www-form-urlencoded"
+
+// formCodec is the `Codec` for `application/x-www-form-urlencoded`. It encodes
+// `url.Values` as well as structs, according to their `query` struct tags, and
+// decodes into a `*url.Values`.
+type formCodec struct{}
+
+var _ Codec = formCodec{}
+
+// ContentType returns `application/x-www-form-urlencoded`
+func (formCodec) ContentType() string {
+	return FormContentType
+}
+
+// Encode writes the URL encoding of `v` to `w`
+func (formCodec) Encode(w io.Writer, v interface{}) error {
+	values, err := encodeQuery(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, values.Encode())
+	return err
+}
+
+// Decode reads a URL-encoded form from `r` and stores it in `v`, which must be
+// a `*url.Values`
+func (formCodec) Decode(r io.Reader, v interface{}) error {
+	dst, ok := v.(*url.Values)
+	if !ok {
+		return fmt.Errorf("cannot decode form into '%T', expected '*url.Values'", v)
+	}
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	values, err := url.ParseQuery(string(buf))
+	if err != nil {
+		return err
+	}
+
+	*dst = values
+	return nil
+}
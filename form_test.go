@@ -0,0 +1,129 @@
+package rhttp
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestEncodeForm(t *testing.T) {
+	type tokenRequest struct {
+		GrantType string   `query:"grant_type"`
+		Scope     []string `query:"scope,comma"`
+		Audience  string   `query:"audience,omitempty"`
+	}
+
+	tcs := []struct {
+		name            string
+		requestFn       requestFn
+		requestCheckFns []requestCheckFn
+		expectedErr     error
+	}{
+		{
+			name: "Values",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeForm(url.Values{"grant_type": {"client_credentials"}, "scope": {"a b"}})
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestBody("grant_type=client_credentials&scope=a+b"),
+				checkRequestHeader("Content-Type", FormContentType),
+			},
+		},
+		{
+			name: "Struct",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeFormStruct(tokenRequest{GrantType: "client_credentials", Scope: []string{"read", "write"}})
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestBody("grant_type=client_credentials&scope=read%2Cwrite"),
+				checkRequestHeader("Content-Type", FormContentType),
+			},
+		},
+		{
+			name: "Codec",
+			requestFn: func(r *Request) *Request {
+				return r.Encode(&tokenRequest{GrantType: "password"}, FormContentType)
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestBody("grant_type=password&scope="),
+				checkRequestHeader("Content-Type", FormContentType),
+			},
+		},
+		{
+			name: "ContentTypeOverridden",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeForm(url.Values{"a": {"1"}}).SetHeader("Content-Type", FormContentType+"; charset=utf-8")
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestHeader("Content-Type", FormContentType+"; charset=utf-8"),
+			},
+		},
+		{
+			name: "NotAStruct",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeFormStruct(42)
+			},
+			expectedErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				requestCheckFns: tc.requestCheckFns,
+				t:               t,
+				doFn:            respondWith(http.StatusOK, nil, nil),
+			})
+
+			_, err := tc.requestFn(c.POST(&url.URL{Scheme: "http", Host: "test.test.test"})).Do().Response()
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeForm(t *testing.T) {
+	tcs := []struct {
+		name        string
+		body        string
+		expected    url.Values
+		expectedErr error
+	}{
+		{
+			name:     "Values",
+			body:     "access_token=abc&token_type=bearer&scope=a+b",
+			expected: url.Values{"access_token": {"abc"}, "token_type": {"bearer"}, "scope": {"a b"}},
+		},
+		{
+			name:     "Empty",
+			body:     "",
+			expected: url.Values{},
+		},
+		{
+			name:        "Malformed",
+			body:        "a=%zz",
+			expectedErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				t:    t,
+				doFn: respondWith(http.StatusOK, []byte(tc.body), nil),
+			})
+
+			_, values, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().DecodeForm()
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, values); diff != "" {
+				t.Errorf("Actual values diverge from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package rhttp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// JSON decodes the response body of the result into a new value of type `T`,
+// as with `Result.DecodeJSON` and the provided options, and returns it. If
+// there was an error anywhere in the chain, it is returned. As long as an HTTP
+// response was generated, it is returned. This function terminates a call
+// chain.
+func JSON[T any](result *Result, opts ...DecodeOption) (T, *http.Response, error) {
+	var v T
+	resp, err := result.DecodeJSON(&v, opts...)
+	return v, resp, err
+}
+
+// Do initializes a `*Request` with the provided context, method & url, encodes
+// the provided body to JSON (unless it is nil), executes the request and
+// decodes the response body into a new value of type `Resp`. The client's
+// configuration, e.g. its base url, default headers and status check, applies
+// as usual.
+func Do[Req, Resp any](ctx context.Context, c *Client, method string, u *url.URL, body Req) (Resp, *http.Response, error) {
+	r := c.NewRequestContext(ctx, method, u)
+	if !isNil(body) {
+		r = r.EncodeJSON(body)
+	}
+
+	return JSON[Resp](r.Do())
+}
+
+// isNil reports whether the value is nil or a nil pointer, map, slice, etc.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
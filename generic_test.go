@@ -0,0 +1,94 @@
+package rhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestJSON(t *testing.T) {
+	u := &url.URL{Scheme: "http", Host: "test.test.test"}
+
+	t.Run("Decodes", func(t *testing.T) {
+		c := NewClient(&mock{t: t, doFn: respondWith(http.StatusOK, jsonPayload(payload{1, "a"}, t), nil)})
+
+		v, resp, err := JSON[payload](c.GET(u).Do())
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if resp == nil {
+			t.Errorf("Expected a non-nil response")
+		}
+		if diff := cmp.Diff(payload{1, "a"}, v); diff != "" {
+			t.Errorf("Actual payload diverges from expectation (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		c := NewClient(&mock{t: t, doFn: respondWith(0, nil, fmt.Errorf("injected error"))})
+
+		v, _, err := JSON[*payload](c.GET(u).Do())
+		if diff := cmp.Diff(cmpopts.AnyError, err, cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+		}
+		if v != nil {
+			t.Errorf("Expected a nil payload but got '%v'", v)
+		}
+	})
+}
+
+func TestDo(t *testing.T) {
+	tcs := []struct {
+		name            string
+		do              func(*Client) (payload, *http.Response, error)
+		requestCheckFns []requestCheckFn
+	}{
+		{
+			name: "WithBody",
+			do: func(c *Client) (payload, *http.Response, error) {
+				return Do[payload, payload](context.Background(), c, http.MethodPost, &url.URL{Path: "/x"}, payload{2, "b"})
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestMethod(http.MethodPost),
+				checkRequestBody("{\"Val1\":2,\"Val2\":\"b\"}\n"),
+			},
+		},
+		{
+			name: "WithNilBody",
+			do: func(c *Client) (payload, *http.Response, error) {
+				return Do[*payload, payload](context.Background(), c, http.MethodGet, &url.URL{Path: "/x"}, nil)
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestMethod(http.MethodGet),
+				func(req *http.Request, t *testing.T) {
+					if req.Body != nil {
+						t.Errorf("Expected no request body")
+					}
+				},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				requestCheckFns: tc.requestCheckFns,
+				t:               t,
+				doFn:            respondWith(http.StatusOK, jsonPayload(payload{1, "a"}, t), nil),
+			}).WithBaseURL(&url.URL{Scheme: "http", Host: "test.test.test"})
+
+			v, _, err := tc.do(c)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(payload{1, "a"}, v); diff != "" {
+				t.Errorf("Actual payload diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
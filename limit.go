@@ -0,0 +1,95 @@
+package rhttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrResponseTooLarge is returned when a response body exceeds the limit set
+// with `WithMaxResponseBytes`
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// WithMaxResponseBytes limits the size of the response body of every request
+// subsequently initialized by the client. Reading more than `n` bytes of a
+// response body fails with `ErrResponseTooLarge`. Zero or a negative value
+// means no limit.
+func (c *Client) WithMaxResponseBytes(n int64) *Client {
+	c.maxResponseBytes = n
+	return c
+}
+
+// WithMaxResponseBytes overrides the client's response body size limit for
+// this request. A response whose `Content-Length` exceeds the limit fails
+// before its body is read. Otherwise, reading more than `n` bytes of the body
+// - with any of the methods of `*Result` - fails with `ErrResponseTooLarge`.
+// Zero or a negative value means no limit.
+func (r *Request) WithMaxResponseBytes(n int64) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	r.maxResponseBytes = n
+	return r
+}
+
+// limitResponseBody enforces the limit upon the response body, failing fast if
+// the response's `Content-Length` exceeds it. The response to a HEAD request
+// has no body, so its `Content-Length` is only that of the would-be body.
+func limitResponseBody(resp *http.Response, limit int64) error {
+	if limit <= 0 || resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return nil
+	}
+
+	if resp.ContentLength > limit {
+		resp.Body.Close()
+		return fmt.Errorf("%w: Content-Length of %d bytes exceeds the limit of %d bytes", ErrResponseTooLarge, resp.ContentLength, limit)
+	}
+
+	resp.Body = &limitedBody{
+		ReadCloser: resp.Body,
+		remaining:  limit,
+		limit:      limit,
+	}
+	return nil
+}
+
+// limitedBody is a response body that fails with `ErrResponseTooLarge` once
+// more than `limit` bytes have been read from it
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+// Read reads from the underlying body. In order to tell whether the body ends
+// exactly at the limit, it reads up to one byte beyond the limit.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, b.err()
+	}
+
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = -1
+		return n, b.err()
+	}
+
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// err is the error returned once the limit is exceeded
+func (b *limitedBody) err() error {
+	return fmt.Errorf("%w: exceeds the limit of %d bytes", ErrResponseTooLarge, b.limit)
+}
@@ -0,0 +1,249 @@
+package rhttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// respondWithLength responds with the provided body and `Content-Length`
+func respondWithLength(body string, contentLength int64) doFn {
+	return func(*http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			ContentLength: contentLength,
+			Body:          &trackingCloser{Reader: strings.NewReader(body)},
+		}, nil
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	consumers := map[string]func(*Result) error{
+		"RawBytes": func(r *Result) error {
+			_, _, err := r.RawBytes()
+			return err
+		},
+		"StreamResponse": func(r *Result) error {
+			_, err := r.StreamResponse(io.Discard)
+			return err
+		},
+		"DecodeJSON": func(r *Result) error {
+			var v interface{}
+			_, err := r.DecodeJSON(&v)
+			return err
+		},
+		"Response": func(r *Result) error {
+			resp, err := r.Response()
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			_, err = io.ReadAll(resp.Body)
+			return err
+		},
+	}
+
+	body := `"0123456789"`
+
+	tcs := []struct {
+		name          string
+		clientLimit   int64
+		requestLimit  *int64
+		contentLength int64
+		expectedErr   error
+	}{
+		{
+			name:          "NoLimit",
+			contentLength: -1,
+		},
+		{
+			name:          "WithinLimit",
+			clientLimit:   int64(len(body)),
+			contentLength: -1,
+		},
+		{
+			name:          "ExceedsLimit",
+			clientLimit:   int64(len(body)) - 1,
+			contentLength: -1,
+			expectedErr:   ErrResponseTooLarge,
+		},
+		{
+			name:          "ContentLengthExceedsLimit",
+			clientLimit:   int64(len(body)) - 1,
+			contentLength: int64(len(body)),
+			expectedErr:   ErrResponseTooLarge,
+		},
+		{
+			name:          "RequestOverridesClient",
+			clientLimit:   int64(len(body)),
+			requestLimit:  func() *int64 { n := int64(4); return &n }(),
+			contentLength: -1,
+			expectedErr:   ErrResponseTooLarge,
+		},
+		{
+			name:          "RequestDisablesLimit",
+			clientLimit:   4,
+			requestLimit:  new(int64),
+			contentLength: -1,
+		},
+	}
+
+	for _, tc := range tcs {
+		for consumerName, consume := range consumers {
+			t.Run(tc.name+"/"+consumerName, func(t *testing.T) {
+				c := NewClient(&mock{
+					t:    t,
+					doFn: respondWithLength(body, tc.contentLength),
+				}).WithMaxResponseBytes(tc.clientLimit)
+
+				r := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"})
+				if tc.requestLimit != nil {
+					r = r.WithMaxResponseBytes(*tc.requestLimit)
+				}
+
+				err := consume(r.Do())
+				if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+					t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+				}
+			})
+		}
+	}
+
+	for method, body := range map[string]io.ReadCloser{
+		http.MethodHead: &trackingCloser{Reader: strings.NewReader("")},
+		http.MethodGet:  http.NoBody,
+	} {
+		t.Run("IgnoresContentLengthWithoutBody/"+method, func(t *testing.T) {
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode:    http.StatusOK,
+						ContentLength: 1 << 40,
+						Body:          body,
+						Request:       req,
+					}, nil
+				},
+			}).WithMaxResponseBytes(1 << 20)
+
+			_, err := c.NewRequest(method, &url.URL{Scheme: "http", Host: "test.test.test"}).Do().Response()
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("FailsFastOnContentLength", func(t *testing.T) {
+		var resp *http.Response
+		c := NewClient(&mock{
+			t: t,
+			doFn: func(req *http.Request) (*http.Response, error) {
+				resp, _ = respondWithLength(body, 1<<40)(req)
+				return resp, nil
+			},
+		}).WithMaxResponseBytes(1 << 20)
+
+		actual, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().Response()
+		if diff := cmp.Diff(ErrResponseTooLarge, err, cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+		}
+		if actual != resp {
+			t.Errorf("Expected the response to be returned alongside the error")
+		}
+		if !resp.Body.(*trackingCloser).closed {
+			t.Errorf("Expected the response body to be closed")
+		}
+	})
+}
+
+func TestMaxResponseBytesWithStatusCheck(t *testing.T) {
+	body := strings.Repeat("x", 1<<20)
+	source := &trackingCloser{Reader: strings.NewReader(body)}
+
+	c := NewClient(&mock{
+		t: t,
+		doFn: func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode:    http.StatusInternalServerError,
+				ContentLength: -1,
+				Body:          source,
+			}, nil
+		},
+	}).WithMaxResponseBytes(16).WithStatusCheck(true)
+
+	resp, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().Response()
+
+	var httpErr *Error
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Expected an *Error, got: %v", err)
+	}
+	if diff := cmp.Diff(body[:16], httpErr.Body); diff != "" {
+		t.Errorf("Actual error body diverges from expectation (-want +got): %s", diff)
+	}
+
+	// only the limit & the byte beyond it may be read from the source
+	if read := len(body) - source.Reader.(*strings.Reader).Len(); read > 17 {
+		t.Errorf("Expected at most 17 bytes to be read from the response, got %d", read)
+	}
+
+	// the rest of the body remains subject to the limit
+	buf, err := io.ReadAll(resp.Body)
+	if diff := cmp.Diff(ErrResponseTooLarge, err, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff(body[:16], string(buf)); diff != "" {
+		t.Errorf("Actual body diverges from expectation (-want +got): %s", diff)
+	}
+}
+
+func TestLimitedBody(t *testing.T) {
+	tcs := []struct {
+		name        string
+		body        string
+		limit       int64
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "Shorter",
+			body:     "abc",
+			limit:    4,
+			expected: "abc",
+		},
+		{
+			name:     "Exact",
+			body:     "abcd",
+			limit:    4,
+			expected: "abcd",
+		},
+		{
+			name:        "Longer",
+			body:        "abcde",
+			limit:       4,
+			expected:    "abcd",
+			expectedErr: ErrResponseTooLarge,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &limitedBody{ReadCloser: io.NopCloser(strings.NewReader(tc.body)), remaining: tc.limit, limit: tc.limit}
+
+			// read one byte at a time, to exercise reads that end at the limit
+			buf, err := io.ReadAll(iotest.OneByteReader(b))
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, string(buf)); diff != "" {
+				t.Errorf("Actual body diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package rhttp
+
+import "net/http"
+
+// Doer executes an `*http.Request`. Both the golang `*http.Client` and every
+// inner http client wrapped by a `Client` are Doers.
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts an ordinary function into a `Doer`
+type DoerFunc func(*http.Request) (*http.Response, error)
+
+var _ Doer = DoerFunc(nil)
+
+// Do invokes the underlying function
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a `Doer` in order to interpose behavior around the
+// execution of a request, e.g. logging, authentication, or metrics. The
+// middleware receives the fully prepared `*http.Request` and may inspect the
+// raw `*http.Response` before it is handled by the `Result`.
+type Middleware func(next Doer) Doer
+
+// Use appends the provided middleware to the client's middleware chain,
+// affecting every request subsequently initialized by the client. Middleware
+// is applied in the order it is registered, i.e. the first registered
+// middleware is the outermost, seeing each request first and each response
+// last. The chain is built once here, rather than for every request.
+func (c *Client) Use(middleware ...Middleware) *Client {
+	c.lazyInitialize()
+	c.middleware = append(c.middleware, middleware...)
+	c.chain = chainMiddleware(c.ci, c.middleware)
+	return c
+}
+
+// chainMiddleware wraps the inner http client in the provided middleware,
+// such that the first middleware is the outermost
+func chainMiddleware(ci httpClientInterface, middleware []Middleware) httpClientInterface {
+	var doer Doer = ci
+	for i := len(middleware) - 1; i >= 0; i-- {
+		doer = middleware[i](doer)
+	}
+	return doer
+}
@@ -0,0 +1,86 @@
+package rhttp
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+":"+req.Header.Get("X-Prepared"))
+				resp, err := next.Do(req)
+				if err == nil {
+					calls = append(calls, name+":"+resp.Status)
+				}
+				return resp, err
+			})
+		}
+	}
+
+	c := NewClient(&mock{
+		t: t,
+		doFn: func(req *http.Request) (*http.Response, error) {
+			calls = append(calls, "inner")
+			resp, err := respondWith(http.StatusOK, []byte("body"), nil)(req)
+			resp.Status = "raw"
+			return resp, err
+		},
+	}).Use(record("first"), record("second")).Use(record("third"))
+
+	_, buf, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).
+		Prepare(func(req *http.Request) error {
+			req.Header.Set("X-Prepared", "yes")
+			return nil
+		}).
+		Do().
+		RawBytes()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]byte("body"), buf); diff != "" {
+		t.Errorf("Actual body diverges from expectation (-want +got): %s", diff)
+	}
+
+	expected := []string{
+		"first:yes",
+		"second:yes",
+		"third:yes",
+		"inner",
+		"third:raw",
+		"second:raw",
+		"first:raw",
+	}
+	if diff := cmp.Diff(expected, calls); diff != "" {
+		t.Errorf("Actual call order diverges from expectation (-want +got): %s", diff)
+	}
+}
+
+func TestMiddlewareChainedOnce(t *testing.T) {
+	constructed := 0
+	count := func(next Doer) Doer {
+		constructed++
+		return next
+	}
+
+	c := NewClient(&mock{
+		t:    t,
+		doFn: respondWith(http.StatusOK, nil, nil),
+	}).Use(count)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().Response(); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+
+	if constructed != 1 {
+		t.Errorf("Expected the middleware to be constructed once, got %d", constructed)
+	}
+}
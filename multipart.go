@@ -0,0 +1,207 @@
+package rhttp
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// Multipart builds a `multipart/form-data` request body, part by part. It is
+// vended by `Request.Multipart` and returns to the request's call chain with
+// `Request`. Like the `*Request` it belongs to, the first error encountered is
+// stored on the request and all subsequent calls do nothing.
+type Multipart struct {
+	r    *Request
+	body *multipartBody
+}
+
+// multipartPart is a part of a multipart body, awaiting to be written
+type multipartPart struct {
+	header  textproto.MIMEHeader
+	content io.Reader
+}
+
+// Multipart sets a `multipart/form-data` request body and returns a builder
+// with which to add its parts. The body is streamed through a pipe as the
+// request is sent, so file parts are never buffered in memory in full - unless
+// the request may be retried, per its retry policy. Unless a `Content-Type`
+// header is specified otherwise, it is set to `multipart/form-data` with the
+// body's boundary.
+func (r *Request) Multipart() *Multipart {
+	m := &Multipart{r: r}
+
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return m
+	}
+
+	pr, pw := io.Pipe()
+	m.body = &multipartBody{
+		pr: pr,
+		pw: pw,
+		mw: multipart.NewWriter(pw),
+	}
+
+	r.WithRequestBody(m.body)
+	r.contentType = m.body.mw.FormDataContentType()
+
+	return m
+}
+
+// AddField adds a form field with the provided name and value
+func (m *Multipart) AddField(name, value string) *Multipart {
+	// do nothing if there is already an error preparing this request
+	if m.r.err != nil {
+		return m
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", formDataDisposition(name, ""))
+
+	return m.addPart("field", name, header, strings.NewReader(value))
+}
+
+// AddFile adds a file with the provided form field name and filename, whose
+// contents are streamed from `content`. The part's `Content-Type` is
+// `application/octet-stream`. If `content` is also an `io.Closer`, it is closed
+// once the body has been written or closed.
+func (m *Multipart) AddFile(name, filename string, content io.Reader) *Multipart {
+	return m.AddFileWithHeader(name, filename, nil, content)
+}
+
+// AddFileWithHeader adds a file like `AddFile`, with additional part headers,
+// e.g. a `Content-Type` other than `application/octet-stream`
+func (m *Multipart) AddFileWithHeader(name, filename string, header textproto.MIMEHeader, content io.Reader) *Multipart {
+	// do nothing if there is already an error preparing this request
+	if m.r.err != nil {
+		return m
+	}
+
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Type", "application/octet-stream")
+	for key, values := range header {
+		partHeader[textproto.CanonicalMIMEHeaderKey(key)] = append([]string(nil), values...)
+	}
+	partHeader.Set("Content-Disposition", formDataDisposition(name, filename))
+
+	return m.addPart("file", name, partHeader, content)
+}
+
+// AddPart adds a part with arbitrary headers, whose contents are streamed from
+// `content`. The caller is responsible for the part's `Content-Disposition`
+// header. If `content` is also an `io.Closer`, it is closed once the body has
+// been written or closed.
+func (m *Multipart) AddPart(header textproto.MIMEHeader, content io.Reader) *Multipart {
+	// do nothing if there is already an error preparing this request
+	if m.r.err != nil {
+		return m
+	}
+
+	partHeader := textproto.MIMEHeader{}
+	for key, values := range header {
+		partHeader[textproto.CanonicalMIMEHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	return m.addPart("part", "#"+fmt.Sprint(len(m.body.parts)), partHeader, content)
+}
+
+// addPart validates and appends a part to the body
+func (m *Multipart) addPart(kind, name string, header textproto.MIMEHeader, content io.Reader) *Multipart {
+	if content == nil {
+		m.r.err = fmt.Errorf("nil content for multipart %s '%s' for '%s %s'", kind, name, m.r.method, m.r.u)
+		return m
+	}
+
+	m.body.parts = append(m.body.parts, multipartPart{
+		header:  header,
+		content: content,
+	})
+
+	return m
+}
+
+// Request returns to the call chain of the request that the multipart body
+// belongs to
+func (m *Multipart) Request() *Request {
+	return m.r
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// formDataDisposition formats a `Content-Disposition` header value for a form
+// field, with a filename if it is non-empty
+func formDataDisposition(name, filename string) string {
+	disposition := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(name))
+	if filename != "" {
+		disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(filename))
+	}
+	return disposition
+}
+
+// multipartBody is a request body that writes its parts into a pipe, from a
+// goroutine that is started upon the first read
+type multipartBody struct {
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+	mw    *multipart.Writer
+	parts []multipartPart
+	once  sync.Once
+}
+
+var _ io.ReadCloser = &multipartBody{}
+
+// Read reads the encoded body, starting to write it if need be
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		go b.write()
+	})
+
+	return b.pr.Read(p)
+}
+
+// Close closes the body, which aborts the writing of any remaining parts
+func (b *multipartBody) Close() error {
+	// if the body was never read, nothing else will release the parts
+	b.once.Do(b.closeParts)
+
+	return b.pr.Close()
+}
+
+// write writes the parts into the pipe, closing it with the first error
+// encountered
+func (b *multipartBody) write() {
+	err := b.writeParts()
+
+	// release the parts before the reader observes the end of the body
+	b.closeParts()
+	b.pw.CloseWithError(err)
+}
+
+// writeParts writes every part and the closing boundary
+func (b *multipartBody) writeParts() error {
+	for _, part := range b.parts {
+		w, err := b.mw.CreatePart(part.header)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(w, part.content)
+		if err != nil {
+			return fmt.Errorf("failed to write multipart part '%s': %w", part.header.Get("Content-Disposition"), err)
+		}
+	}
+
+	return b.mw.Close()
+}
+
+// closeParts closes the contents of every part that is an `io.Closer`
+func (b *multipartBody) closeParts() {
+	for _, part := range b.parts {
+		if closer, ok := part.content.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
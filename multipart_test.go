@@ -0,0 +1,305 @@
+package rhttp
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// multipartPartSummary is the comparable content of a part of a multipart body
+type multipartPartSummary struct {
+	Header  textproto.MIMEHeader
+	Content string
+}
+
+// readMultipart reads the parts of a multipart request body
+func readMultipart(req *http.Request) ([]multipartPartSummary, error) {
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	if mediaType != "multipart/form-data" {
+		return nil, errors.New("unexpected media type " + mediaType)
+	}
+
+	var parts []multipartPartSummary
+	mr := multipart.NewReader(req.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, multipartPartSummary{Header: part.Header, Content: string(content)})
+	}
+}
+
+// trackingCloser records whether it was closed
+type trackingCloser struct {
+	io.Reader
+	closed bool
+}
+
+func (c *trackingCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+var errFileRead = errors.New("file read error")
+
+func TestMultipart(t *testing.T) {
+	tcs := []struct {
+		name          string
+		requestFn     requestFn
+		expectedParts []multipartPartSummary
+		expectedErr   error
+	}{
+		{
+			name: "FieldsAndFiles",
+			requestFn: func(r *Request) *Request {
+				return r.Multipart().
+					AddField("title", "report").
+					AddFile("upload", `q3 "final".csv`, strings.NewReader("a,b\n1,2\n")).
+					AddFileWithHeader("image", "logo.png", textproto.MIMEHeader{"content-type": {"image/png"}}, strings.NewReader("PNG")).
+					AddPart(textproto.MIMEHeader{"Content-Disposition": {`form-data; name="meta"`}, "Content-Type": {"application/json"}}, strings.NewReader("{}")).
+					Request()
+			},
+			expectedParts: []multipartPartSummary{
+				{
+					Header:  textproto.MIMEHeader{"Content-Disposition": {`form-data; name="title"`}},
+					Content: "report",
+				},
+				{
+					Header: textproto.MIMEHeader{
+						"Content-Disposition": {`form-data; name="upload"; filename="q3 \"final\".csv"`},
+						"Content-Type":        {"application/octet-stream"},
+					},
+					Content: "a,b\n1,2\n",
+				},
+				{
+					Header: textproto.MIMEHeader{
+						"Content-Disposition": {`form-data; name="image"; filename="logo.png"`},
+						"Content-Type":        {"image/png"},
+					},
+					Content: "PNG",
+				},
+				{
+					Header: textproto.MIMEHeader{
+						"Content-Disposition": {`form-data; name="meta"`},
+						"Content-Type":        {"application/json"},
+					},
+					Content: "{}",
+				},
+			},
+		},
+		{
+			name: "Empty",
+			requestFn: func(r *Request) *Request {
+				return r.Multipart().Request()
+			},
+		},
+		{
+			name: "NilContent",
+			requestFn: func(r *Request) *Request {
+				return r.Multipart().AddFile("upload", "a.txt", nil).AddField("title", "report").Request()
+			},
+			expectedErr: cmpopts.AnyError,
+		},
+		{
+			name: "ErrorAlreadySet",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeJSON(&unencodablePayload{}).Multipart().AddField("title", "report").Request()
+			},
+			expectedErr: errUnencodable,
+		},
+		{
+			name: "ContentReadFailure",
+			requestFn: func(r *Request) *Request {
+				return r.Multipart().AddFile("upload", "a.txt", iotest.ErrReader(errFileRead)).Request()
+			},
+			expectedErr: errFileRead,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					parts, err := readMultipart(req)
+					if err != nil {
+						return nil, err
+					}
+					if diff := cmp.Diff(tc.expectedParts, parts); diff != "" {
+						t.Errorf("Actual parts diverge from expectation (-want +got): %s", diff)
+					}
+					return respondWith(http.StatusOK, nil, nil)(req)
+				},
+			})
+
+			_, err := tc.requestFn(c.POST(&url.URL{Scheme: "http", Host: "test.test.test"})).Do().Response()
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestMultipartClosesContent(t *testing.T) {
+	t.Run("Written", func(t *testing.T) {
+		file := &trackingCloser{Reader: strings.NewReader("contents")}
+		c := NewClient(&mock{
+			t: t,
+			doFn: func(req *http.Request) (*http.Response, error) {
+				_, err := io.Copy(io.Discard, req.Body)
+				req.Body.Close()
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, err
+			},
+		})
+
+		_, err := c.POST(&url.URL{Scheme: "http", Host: "test.test.test"}).Multipart().AddFile("upload", "a.txt", file).Request().Do().Response()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !file.closed {
+			t.Errorf("Expected the file to be closed once written")
+		}
+	})
+
+	notSentTcs := []struct {
+		name      string
+		requestFn func(r *Request, file io.Reader) *Request
+	}{
+		{
+			name: "ErrorAlreadySet",
+			requestFn: func(r *Request, file io.Reader) *Request {
+				return r.Multipart().AddFile("upload", "a.txt", file).AddField("title", "report").Request().EncodeQuery(42)
+			},
+		},
+		{
+			name: "UnfilledPathParam",
+			requestFn: func(r *Request, file io.Reader) *Request {
+				r.u.Path = "/uploads/{id}"
+				return r.Multipart().AddFile("upload", "a.txt", file).Request()
+			},
+		},
+		{
+			name: "PrepareFailure",
+			requestFn: func(r *Request, file io.Reader) *Request {
+				return r.Multipart().AddFile("upload", "a.txt", file).Request().Prepare(func(*http.Request) error {
+					return errFileRead
+				})
+			},
+		},
+	}
+
+	for _, tc := range notSentTcs {
+		t.Run("NotSent/"+tc.name, func(t *testing.T) {
+			file := &trackingCloser{Reader: strings.NewReader("contents")}
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					t.Errorf("Did not expect the request to be sent")
+					return respondWith(http.StatusOK, nil, nil)(req)
+				},
+			})
+
+			_, err := tc.requestFn(c.POST(&url.URL{Scheme: "http", Host: "test.test.test"}), file).Do().Response()
+			if err == nil {
+				t.Errorf("Expected an error")
+			}
+			if !file.closed {
+				t.Errorf("Expected the file to be closed although the request was not sent")
+			}
+		})
+	}
+
+	t.Run("NeverRead", func(t *testing.T) {
+		file := &trackingCloser{Reader: strings.NewReader("contents")}
+		c := NewClient(&mock{
+			t: t,
+			doFn: func(req *http.Request) (*http.Response, error) {
+				req.Body.Close()
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			},
+		})
+
+		_, err := c.POST(&url.URL{Scheme: "http", Host: "test.test.test"}).Multipart().AddFile("upload", "a.txt", file).Request().Do().Response()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !file.closed {
+			t.Errorf("Expected the file to be closed along with the body")
+		}
+	})
+}
+
+func TestMultipartRetry(t *testing.T) {
+	file := &trackingCloser{Reader: strings.NewReader("contents")}
+
+	var attempts [][]multipartPartSummary
+	c := NewClient(&mock{
+		t: t,
+		doFn: func(req *http.Request) (*http.Response, error) {
+			parts, err := readMultipart(req)
+			if err != nil {
+				return nil, err
+			}
+			attempts = append(attempts, parts)
+
+			statusCode := http.StatusServiceUnavailable
+			if len(attempts) > 1 {
+				statusCode = http.StatusOK
+			}
+			return respondWith(statusCode, nil, nil)(req)
+		},
+	}).WithRetryPolicy(fastRetryPolicy(3))
+
+	_, err := c.PUT(&url.URL{Scheme: "http", Host: "test.test.test"}).
+		Multipart().
+		AddField("title", "report").
+		AddFile("upload", "a.txt", file).
+		Request().
+		Do().
+		Response()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := []multipartPartSummary{
+		{
+			Header:  textproto.MIMEHeader{"Content-Disposition": {`form-data; name="title"`}},
+			Content: "report",
+		},
+		{
+			Header: textproto.MIMEHeader{
+				"Content-Disposition": {`form-data; name="upload"; filename="a.txt"`},
+				"Content-Type":        {"application/octet-stream"},
+			},
+			Content: "contents",
+		},
+	}
+	if diff := cmp.Diff([][]multipartPartSummary{expected, expected}, attempts); diff != "" {
+		t.Errorf("Actual attempts diverge from expectation (-want +got): %s", diff)
+	}
+	if !file.closed {
+		t.Errorf("Expected the file to be closed once buffered")
+	}
+}
@@ -0,0 +1,193 @@
+package rhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the media type of a problem details document, as
+// defined by RFC 9457 (which obsoletes RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// problemMembers are the members of a problem details document that map onto
+// dedicated fields of an `*Error`, rather than onto its extensions
+var problemMembers = map[string]bool{
+	"type":     true,
+	"title":    true,
+	"status":   true,
+	"detail":   true,
+	"instance": true,
+}
+
+var (
+	_ json.Marshaler   = &Error{}
+	_ json.Unmarshaler = &Error{}
+)
+
+// MarshalJSON encodes the error as a problem details document (RFC 9457). The
+// title defaults to the generic status text for the status code and the
+// detail defaults to the error's message, unless that message is itself the
+// generic status text. Extension members never replace the standard members.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]interface{}, len(e.Extensions)+5)
+	for key, value := range e.Extensions {
+		if !problemMembers[key] {
+			doc[key] = value
+		}
+	}
+
+	if e.Type != "" {
+		doc["type"] = e.Type
+	}
+
+	title := e.Title
+	if title == "" {
+		title = http.StatusText(e.StatusCode)
+	}
+	if title != "" {
+		doc["title"] = title
+	}
+
+	if e.StatusCode != 0 {
+		doc["status"] = e.StatusCode
+	}
+
+	detail := e.Detail
+	if detail == "" && e.Message != http.StatusText(e.StatusCode) {
+		detail = e.Message
+	}
+	if detail != "" {
+		doc["detail"] = detail
+	}
+
+	if e.Instance != "" {
+		doc["instance"] = e.Instance
+	}
+
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON decodes a problem details document (RFC 9457) into the error.
+// Members other than the standard ones are kept as extensions. The error's
+// message is set to the detail or, lacking that, the title. For backward
+// compatibility, a document without any standard members is decoded in the
+// error's former JSON encoding, i.e. its `StatusCode` and `Message` members
+// are decoded into the respective fields rather than kept as extensions.
+func (e *Error) UnmarshalJSON(buf []byte) error {
+	var doc map[string]json.RawMessage
+	err := json.Unmarshal(buf, &doc)
+	if err != nil {
+		return err
+	}
+
+	var problem struct {
+		Type, Title, Detail, Instance string
+		Status                        int
+	}
+
+	var legacy struct {
+		StatusCode int
+		Message    string
+	}
+
+	// N.B. per the RFC, members with a value of the wrong type are ignored, so
+	// decode each standard member individually
+	for key, dst := range map[string]interface{}{
+		"type":     &problem.Type,
+		"title":    &problem.Title,
+		"status":   &problem.Status,
+		"detail":   &problem.Detail,
+		"instance": &problem.Instance,
+	} {
+		if raw, ok := doc[key]; ok {
+			_ = json.Unmarshal(raw, dst)
+		}
+	}
+
+	legacyEncoding := true
+	for key := range doc {
+		if problemMembers[key] {
+			legacyEncoding = false
+			break
+		}
+	}
+
+	var extensions map[string]interface{}
+	for key, raw := range doc {
+		if problemMembers[key] {
+			continue
+		}
+
+		// N.B. like `encoding/json`, match the former field names
+		// case-insensitively
+		if legacyEncoding {
+			switch strings.ToLower(key) {
+			case "statuscode":
+				_ = json.Unmarshal(raw, &legacy.StatusCode)
+				continue
+			case "message":
+				_ = json.Unmarshal(raw, &legacy.Message)
+				continue
+			}
+		}
+
+		var value interface{}
+		err = json.Unmarshal(raw, &value)
+		if err != nil {
+			return fmt.Errorf("failed to decode problem extension member '%s': %w", key, err)
+		}
+
+		if extensions == nil {
+			extensions = make(map[string]interface{})
+		}
+		extensions[key] = value
+	}
+
+	e.Type = problem.Type
+	e.Title = problem.Title
+	e.Detail = problem.Detail
+	e.Instance = problem.Instance
+	e.Extensions = extensions
+	switch {
+	case problem.Status != 0:
+		e.StatusCode = problem.Status
+	case legacy.StatusCode != 0:
+		e.StatusCode = legacy.StatusCode
+	}
+
+	switch {
+	case problem.Detail != "":
+		e.Message = problem.Detail
+	case legacy.Message != "":
+		e.Message = legacy.Message
+	case problem.Title != "":
+		e.Message = problem.Title
+	case e.Message == "":
+		e.Message = http.StatusText(e.StatusCode)
+	}
+
+	return nil
+}
+
+// isProblem reports whether the response carries a problem details document
+func isProblem(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == ProblemContentType
+}
+
+// parseProblem decodes a problem details document from the body of a response
+// with the provided status code. The status code of the response is
+// authoritative over the status member of the document.
+func parseProblem(statusCode int, body []byte) (*Error, error) {
+	e := &Error{}
+	err := json.Unmarshal(body, e)
+	if err != nil {
+		return nil, err
+	}
+
+	e.StatusCode = statusCode
+	return e, nil
+}
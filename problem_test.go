@@ -0,0 +1,178 @@
+package rhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProblemMarshalJSON(t *testing.T) {
+	tcs := []struct {
+		name     string
+		err      *Error
+		expected string
+	}{
+		{
+			name:     "Generic",
+			err:      ErrNotFound,
+			expected: `{"status":404,"title":"Not Found"}`,
+		},
+		{
+			name:     "Message",
+			err:      ErrNotFound.New("no such user"),
+			expected: `{"detail":"no such user","status":404,"title":"Not Found"}`,
+		},
+		{
+			name: "Problem",
+			err: &Error{
+				StatusCode: http.StatusForbidden,
+				Message:    "ignored",
+				Type:       "https://example.com/probs/out-of-credit",
+				Title:      "You do not have enough credit.",
+				Detail:     "Your current balance is 30, but that costs 50.",
+				Instance:   "/account/12345/msgs/abc",
+				Extensions: map[string]interface{}{
+					"balance": 30,
+					"status":  500,
+				},
+			},
+			expected: `{"balance":30,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc","status":403,"title":"You do not have enough credit.","type":"https://example.com/probs/out-of-credit"}`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := json.Marshal(tc.err)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, string(actual)); diff != "" {
+				t.Errorf("Actual JSON diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestProblemUnmarshalJSON(t *testing.T) {
+	tcs := []struct {
+		name     string
+		doc      string
+		expected *Error
+	}{
+		{
+			name: "Problem",
+			doc:  `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc","balance":30,"accounts":["/account/12345"]}`,
+			expected: &Error{
+				StatusCode: http.StatusForbidden,
+				Message:    "Your current balance is 30, but that costs 50.",
+				Type:       "https://example.com/probs/out-of-credit",
+				Title:      "You do not have enough credit.",
+				Detail:     "Your current balance is 30, but that costs 50.",
+				Instance:   "/account/12345/msgs/abc",
+				Extensions: map[string]interface{}{
+					"balance":  float64(30),
+					"accounts": []interface{}{"/account/12345"},
+				},
+			},
+		},
+		{
+			name: "TitleOnly",
+			doc:  `{"title":"Not Found","status":404}`,
+			expected: &Error{
+				StatusCode: http.StatusNotFound,
+				Message:    "Not Found",
+				Title:      "Not Found",
+			},
+		},
+		{
+			name: "LegacyEncoding",
+			doc:  `{"StatusCode":404,"Message":"x"}`,
+			expected: &Error{
+				StatusCode: http.StatusNotFound,
+				Message:    "x",
+			},
+		},
+		{
+			name: "LegacyEncodingCaseInsensitive",
+			doc:  `{"statusCode":409,"message":"conflict","extra":true}`,
+			expected: &Error{
+				StatusCode: http.StatusConflict,
+				Message:    "conflict",
+				Extensions: map[string]interface{}{"extra": true},
+			},
+		},
+		{
+			name: "MessageExtensionOfProblem",
+			doc:  `{"status":400,"detail":"invalid","message":"kept"}`,
+			expected: &Error{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid",
+				Detail:     "invalid",
+				Extensions: map[string]interface{}{"message": "kept"},
+			},
+		},
+		{
+			name: "WrongMemberTypesIgnored",
+			doc:  `{"title":42,"status":"404","detail":"missing"}`,
+			expected: &Error{
+				Message: "missing",
+				Detail:  "missing",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := &Error{}
+			err := json.Unmarshal([]byte(tc.doc), actual)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestProblemResponse(t *testing.T) {
+	c := NewClient(&mock{
+		t: t,
+		doFn: func(req *http.Request) (*http.Response, error) {
+			resp, err := respondWith(
+				http.StatusConflict,
+				[]byte(`{"title":"Conflict","status":400,"detail":"already exists","id":"42"}`),
+				nil,
+			)(req)
+			resp.Header = http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}}
+			return resp, err
+		},
+	}).WithStatusCheck(true)
+
+	_, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().Response()
+
+	var httpErr *Error
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Expected error '%v' to wrap an *Error", err)
+	}
+
+	expected := &Error{
+		StatusCode: http.StatusConflict,
+		Message:    "already exists",
+		Title:      "Conflict",
+		Detail:     "already exists",
+		Extensions: map[string]interface{}{"id": "42"},
+
+		Method:         http.MethodGet,
+		URL:            "http://test.test.test",
+		ResponseHeader: http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+		Body:           `{"title":"Conflict","status":400,"detail":"already exists","id":"42"}`,
+	}
+	if diff := cmp.Diff(expected, httpErr); diff != "" {
+		t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,231 @@
+package rhttp
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// encodeQuery encodes the provided struct (or pointer to struct) into
+// `url.Values`, according to the `query` struct tags of its fields. The tag
+// value is the name of the query parameter, optionally followed by
+// comma-separated options:
+//
+//   - `omitempty` omits the parameter if the field has a zero value
+//   - `comma` encodes a slice as a single comma-joined value, rather than as
+//     a repeated parameter
+//   - `unix` & `unixmilli` encode a `time.Time` as a unix timestamp in
+//     seconds or milliseconds, respectively
+//
+// A field without a tag uses its own name and a field tagged `query:"-"` is
+// skipped. A `time.Time` is formatted per the `layout` struct tag, if any, and
+// as RFC 3339 otherwise. Fields of embedded structs are encoded as if they
+// belonged to the outer struct. Types that implement `encoding.TextMarshaler`
+// are encoded as text. Nil pointers are always omitted.
+func encodeQuery(v interface{}) (url.Values, error) {
+	values := url.Values{}
+	if v == nil {
+		return values, nil
+	}
+
+	if vs, ok := v.(url.Values); ok {
+		for key, vals := range vs {
+			values[key] = append([]string(nil), vals...)
+		}
+		return values, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return values, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot encode type '%v' as query parameters, expected a struct", rv.Type())
+	}
+
+	err := encodeQueryStruct(values, rv)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// encodeQueryStruct adds the fields of the struct value `rv` to `values`
+func encodeQueryStruct(values url.Values, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		tag := field.Tag.Get("query")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := parseQueryTag(tag)
+
+		// flatten embedded structs, unless they are explicitly named
+		if field.Anonymous && name == "" {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType && !fv.Type().Implements(textMarshalerType) {
+				err := encodeQueryStruct(values, fv)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+
+		if (fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface) && fv.IsNil() {
+			continue
+		}
+
+		if opts.contains("omitempty") && isEmptyQueryValue(fv) {
+			continue
+		}
+
+		if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && !fv.Type().Implements(textMarshalerType) {
+			var strs []string
+			for j := 0; j < fv.Len(); j++ {
+				str, err := formatQueryValue(fv.Index(j), field.Tag, opts)
+				if err != nil {
+					return fmt.Errorf("failed to encode query parameter '%s': %w", name, err)
+				}
+				strs = append(strs, str)
+			}
+
+			if opts.contains("comma") {
+				values.Add(name, strings.Join(strs, ","))
+			} else {
+				for _, str := range strs {
+					values.Add(name, str)
+				}
+			}
+			continue
+		}
+
+		str, err := formatQueryValue(fv, field.Tag, opts)
+		if err != nil {
+			return fmt.Errorf("failed to encode query parameter '%s': %w", name, err)
+		}
+		values.Add(name, str)
+	}
+
+	return nil
+}
+
+// isEmptyQueryValue reports whether the value should be omitted by
+// `omitempty`
+func isEmptyQueryValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.IsZero()
+	}
+
+	return v.IsZero()
+}
+
+// formatQueryValue formats a single scalar value as a query parameter value
+func formatQueryValue(v reflect.Value, tag reflect.StructTag, opts queryTagOptions) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		switch {
+		case opts.contains("unix"):
+			return strconv.FormatInt(t.Unix(), 10), nil
+		case opts.contains("unixmilli"):
+			return strconv.FormatInt(t.UnixMilli(), 10), nil
+		case tag.Get("layout") != "":
+			return t.Format(tag.Get("layout")), nil
+		default:
+			return t.Format(time.RFC3339), nil
+		}
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	}
+
+	return "", fmt.Errorf("unsupported type '%v'", v.Type())
+}
+
+// queryTagOptions holds the options that follow the name in a `query` tag
+type queryTagOptions []string
+
+func (o queryTagOptions) contains(opt string) bool {
+	for _, o := range o {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQueryTag splits a `query` struct tag into its name and options
+func parseQueryTag(tag string) (string, queryTagOptions) {
+	parts := strings.Split(tag, ",")
+	return parts[0], queryTagOptions(parts[1:])
+}
@@ -0,0 +1,149 @@
+package rhttp
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type queryPage struct {
+	Page    int `query:"page,omitempty"`
+	PerPage int `query:"per_page,omitempty"`
+}
+
+type queryFilter struct {
+	queryPage
+	Name     string    `query:"name"`
+	Tags     []string  `query:"tag"`
+	IDs      []int     `query:"ids,comma,omitempty"`
+	Active   *bool     `query:"active"`
+	Since    time.Time `query:"since,omitempty"`
+	Until    time.Time `query:"until,unix"`
+	Day      time.Time `query:"day" layout:"2006-01-02"`
+	Score    float64   `query:"score,omitempty"`
+	Skipped  string    `query:"-"`
+	Untagged uint
+	private  string
+}
+
+func TestEncodeQuery(t *testing.T) {
+	active := true
+	ts := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tcs := []struct {
+		name        string
+		v           interface{}
+		expected    url.Values
+		expectedErr error
+	}{
+		{
+			name: "Struct",
+			v: queryFilter{
+				queryPage: queryPage{Page: 2},
+				Name:      "a b",
+				Tags:      []string{"x", "y"},
+				IDs:       []int{1, 2, 3},
+				Active:    &active,
+				Since:     ts,
+				Until:     ts,
+				Day:       ts,
+				Score:     1.5,
+				Skipped:   "skipped",
+				Untagged:  7,
+				private:   "private",
+			},
+			expected: url.Values{
+				"page":     {"2"},
+				"name":     {"a b"},
+				"tag":      {"x", "y"},
+				"ids":      {"1,2,3"},
+				"active":   {"true"},
+				"since":    {"2022-03-04T05:06:07Z"},
+				"until":    {"1646370367"},
+				"day":      {"2022-03-04"},
+				"score":    {"1.5"},
+				"Untagged": {"7"},
+			},
+		},
+		{
+			name: "ZeroStruct",
+			v:    &queryFilter{},
+			expected: url.Values{
+				"name":     {""},
+				"until":    {"-62135596800"},
+				"day":      {"0001-01-01"},
+				"Untagged": {"0"},
+			},
+		},
+		{
+			name:     "NilPointer",
+			v:        (*queryFilter)(nil),
+			expected: url.Values{},
+		},
+		{
+			name:     "Values",
+			v:        url.Values{"a": {"1", "2"}},
+			expected: url.Values{"a": {"1", "2"}},
+		},
+		{
+			name:        "NotAStruct",
+			v:           42,
+			expectedErr: cmpopts.AnyError,
+		},
+		{
+			name: "UnsupportedField",
+			v: struct {
+				M map[string]string
+			}{M: map[string]string{}},
+			expectedErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := encodeQuery(tc.v)
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Actual values diverge from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRequestQuery(t *testing.T) {
+	var actual url.Values
+	c := NewClient(&mock{
+		t: t,
+		doFn: func(req *http.Request) (*http.Response, error) {
+			actual = req.URL.Query()
+			return respondWith(http.StatusOK, nil, nil)(req)
+		},
+	})
+
+	u := mustParseURL("https://example.com/search?q=x", t)
+	_, err := c.GET(u).
+		WithQueryParam("a", "1").
+		WithQueryParam("a", "2").
+		WithQuery(url.Values{"b": {"3"}}).
+		EncodeQuery(queryPage{Page: 4}).
+		Do().
+		Response()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expected := url.Values{"q": {"x"}, "a": {"1", "2"}, "b": {"3"}, "page": {"4"}}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("Actual query diverges from expectation (-want +got): %s", diff)
+	}
+
+	if diff := cmp.Diff("https://example.com/search?q=x", u.String()); diff != "" {
+		t.Errorf("Caller's url was mutated (-want +got): %s", diff)
+	}
+}
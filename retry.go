@@ -0,0 +1,200 @@
+package rhttp
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures the automatic retry of failed requests. A request is
+// retried if the inner http client returns an error or a response with a
+// retryable status code, until it succeeds or `MaxAttempts` is reached. The
+// delay between attempts grows exponentially from `BaseDelay` up to
+// `MaxDelay`, with full jitter. If a 429 or 503 response has a `Retry-After`
+// header, its delay is honored instead; if that delay exceeds `MaxDelay`, the
+// response is not retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+
+	// BaseDelay is the upper bound on the delay before the first retry, which
+	// then doubles for each subsequent retry
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between attempts. Zero means no cap.
+	MaxDelay time.Duration
+
+	// RetryableStatusCodes are the response status codes that are retried. If
+	// nil, 408, 429, 500, 502, 503 & 504 are retried.
+	RetryableStatusCodes []int
+
+	// RetryableError reports whether an error returned by the inner http
+	// client is retried. If nil, all errors are retried. Errors due to the
+	// request's context being done are never retried.
+	RetryableError func(error) bool
+
+	// RetryNonIdempotent permits retrying requests whose method is not
+	// idempotent, e.g. POST & PATCH. By default, only idempotent requests are
+	// retried.
+	RetryNonIdempotent bool
+}
+
+// NewRetryPolicy vends a `*RetryPolicy` with the provided maximum number of
+// attempts and reasonable defaults for everything else
+func NewRetryPolicy(maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+	}
+}
+
+// WithRetryPolicy sets the retry policy of every request subsequently
+// initialized by the client. A nil policy disables retries.
+func (c *Client) WithRetryPolicy(policy *RetryPolicy) *Client {
+	c.retryPolicy = policy
+	return c
+}
+
+// WithRetryPolicy overrides the client's retry policy for this request. A nil
+// policy disables retries.
+func (r *Request) WithRetryPolicy(policy *RetryPolicy) *Request {
+	// do nothing if there is already an error preparing this request
+	if r.err != nil {
+		return r
+	}
+
+	r.retryPolicy = policy
+	return r
+}
+
+// allowsRetries reports whether the policy may ever retry a request with the
+// provided method
+func (p *RetryPolicy) allowsRetries(method string) bool {
+	return p != nil && p.MaxAttempts > 1 && (p.RetryNonIdempotent || isIdempotent(method))
+}
+
+// retryDelay reports whether the outcome of the numbered attempt (starting at
+// 1) should be retried and, if so, how long to wait beforehand
+func (p *RetryPolicy) retryDelay(attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if !p.allowsRetries(req.Method) || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || req.Context().Err() != nil {
+			return 0, false
+		}
+		if p.RetryableError != nil && !p.RetryableError(err) {
+			return 0, false
+		}
+		return p.backoff(attempt), true
+	}
+
+	if !p.isRetryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if p.MaxDelay > 0 && delay > p.MaxDelay {
+				return 0, false
+			}
+			return delay, true
+		}
+	}
+
+	return p.backoff(attempt), true
+}
+
+// isRetryableStatus reports whether responses with the provided status code
+// are retried
+func (p *RetryPolicy) isRetryableStatus(statusCode int) bool {
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = retryableStatusCodes
+	}
+
+	for _, code := range codes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff computes the exponential delay with full jitter before the retry
+// that follows the numbered attempt
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay) && delay < time.Duration(1<<62); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// isIdempotent reports whether the request method is idempotent per RFC 7231
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses the value of a `Retry-After` header, which is either
+// a number of seconds or an HTTP-date, into the delay relative to `now`. A
+// date in the past yields a zero delay.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > int64(time.Duration(1<<63-1)/time.Second) {
+			return time.Duration(1<<63 - 1), true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	delay := date.Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+// sleepContext waits for the provided duration or until the context is done,
+// whichever happens first, returning the context's error in the latter case
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
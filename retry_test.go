@@ -0,0 +1,344 @@
+package rhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func fastRetryPolicy(maxAttempts int) *RetryPolicy {
+	policy := NewRetryPolicy(maxAttempts)
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 5 * time.Millisecond
+	return policy
+}
+
+// respondInSequence responds with each of the provided functions in turn,
+// recording the request bodies received
+func respondInSequence(bodies *[]string, fns ...doFn) doFn {
+	attempt := 0
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			buf, _ := io.ReadAll(req.Body)
+			*bodies = append(*bodies, string(buf))
+		} else {
+			*bodies = append(*bodies, "")
+		}
+
+		fn := fns[attempt]
+		if attempt < len(fns)-1 {
+			attempt++
+		}
+		return fn(req)
+	}
+}
+
+func respondWithHeader(statusCode int, header http.Header) doFn {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := respondWith(statusCode, nil, nil)(req)
+		resp.Header = header
+		return resp, err
+	}
+}
+
+func TestRetry(t *testing.T) {
+	u := &url.URL{Scheme: "http", Host: "test.test.test"}
+	unavailable := respondWith(http.StatusServiceUnavailable, []byte("unavailable"), nil)
+	ok := respondWith(http.StatusOK, []byte("ok"), nil)
+	networkErr := respondWith(0, nil, fmt.Errorf("injected error"))
+
+	tcs := []struct {
+		name               string
+		method             string
+		clientPolicy       *RetryPolicy
+		requestFn          requestFn
+		doFns              []doFn
+		expectedBodies     []string
+		expectedStatusCode int
+		expectedErr        bool
+	}{
+		{
+			name:         "RetriesUntilSuccess",
+			clientPolicy: fastRetryPolicy(3),
+			requestFn: func(r *Request) *Request {
+				return r.EncodeJSON(payload{1, "a"})
+			},
+			doFns:              []doFn{unavailable, networkErr, ok},
+			expectedBodies:     []string{"{\"Val1\":1,\"Val2\":\"a\"}\n", "{\"Val1\":1,\"Val2\":\"a\"}\n", "{\"Val1\":1,\"Val2\":\"a\"}\n"},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:         "BuffersOneShotBody",
+			clientPolicy: fastRetryPolicy(2),
+			requestFn: func(r *Request) *Request {
+				return r.WithRequestBody(io.NopCloser(bytes.NewBufferString("body")))
+			},
+			doFns:              []doFn{unavailable, ok},
+			expectedBodies:     []string{"body", "body"},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "GivesUpAfterMaxAttempts",
+			clientPolicy:       fastRetryPolicy(2),
+			doFns:              []doFn{unavailable},
+			expectedBodies:     []string{"", ""},
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "GivesUpOnNetworkError",
+			clientPolicy:   fastRetryPolicy(2),
+			doFns:          []doFn{networkErr},
+			expectedBodies: []string{"", ""},
+			expectedErr:    true,
+		},
+		{
+			name:               "DoesNotRetryOtherStatusCodes",
+			clientPolicy:       fastRetryPolicy(3),
+			doFns:              []doFn{respondWith(http.StatusNotFound, nil, nil), ok},
+			expectedBodies:     []string{""},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "DoesNotRetryNonIdempotentByDefault",
+			method:             http.MethodPost,
+			clientPolicy:       fastRetryPolicy(3),
+			doFns:              []doFn{unavailable, ok},
+			expectedBodies:     []string{""},
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:         "RetriesNonIdempotentWhenPermitted",
+			method:       http.MethodPost,
+			clientPolicy: fastRetryPolicy(3),
+			requestFn: func(r *Request) *Request {
+				policy := fastRetryPolicy(3)
+				policy.RetryNonIdempotent = true
+				return r.WithRetryPolicy(policy)
+			},
+			doFns:              []doFn{unavailable, ok},
+			expectedBodies:     []string{"", ""},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:         "RequestOverridesClient",
+			clientPolicy: fastRetryPolicy(3),
+			requestFn: func(r *Request) *Request {
+				return r.WithRetryPolicy(nil)
+			},
+			doFns:              []doFn{unavailable, ok},
+			expectedBodies:     []string{""},
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:         "HonorsRetryAfter",
+			clientPolicy: fastRetryPolicy(2),
+			doFns: []doFn{
+				respondWithHeader(http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}),
+				ok,
+			},
+			expectedBodies:     []string{"", ""},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:         "DoesNotRetryWhenRetryAfterExceedsMaxDelay",
+			clientPolicy: fastRetryPolicy(2),
+			doFns: []doFn{
+				respondWithHeader(http.StatusTooManyRequests, http.Header{"Retry-After": {"120"}}),
+				ok,
+			},
+			expectedBodies:     []string{""},
+			expectedStatusCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var bodies []string
+			c := NewClient(&mock{
+				t:    t,
+				doFn: respondInSequence(&bodies, tc.doFns...),
+			}).WithRetryPolicy(tc.clientPolicy)
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			r := c.NewRequest(method, u)
+			if tc.requestFn != nil {
+				r = tc.requestFn(r)
+			}
+
+			resp, err := r.Do().Response()
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			} else if diff := cmp.Diff(tc.expectedStatusCode, resp.StatusCode); diff != "" {
+				t.Errorf("Actual status code diverges from expectation (-want +got): %s", diff)
+			}
+
+			if diff := cmp.Diff(tc.expectedBodies, bodies); diff != "" {
+				t.Errorf("Actual request bodies diverge from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+
+	t.Run("AbortsWhenContextIsDone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		policy := NewRetryPolicy(3)
+		policy.BaseDelay = time.Hour
+		policy.MaxDelay = 0
+
+		c := NewClient(&mock{
+			t: t,
+			doFn: func(req *http.Request) (*http.Response, error) {
+				cancel()
+				return unavailable(req)
+			},
+		}).WithRetryPolicy(policy)
+
+		_, err := c.GET(u).DoContext(ctx).Response()
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected error '%v' to wrap '%v'", err, context.Canceled)
+		}
+	})
+
+	t.Run("NilResponseBody", func(t *testing.T) {
+		attempts := 0
+		c := NewClient(DoerFunc(func(*http.Request) (*http.Response, error) {
+			attempts++
+			return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+		})).WithRetryPolicy(fastRetryPolicy(3))
+
+		resp, err := c.GET(u).Do().Response()
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Expected status code %d but got %d", http.StatusServiceUnavailable, resp.StatusCode)
+		}
+		if attempts != 3 {
+			t.Errorf("Expected 3 attempts but got %d", attempts)
+		}
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	for attempt := 1; attempt < 100; attempt++ {
+		expectedMax := 10 * time.Millisecond << (attempt - 1)
+		if attempt > 3 {
+			expectedMax = 50 * time.Millisecond
+		}
+
+		delay := policy.backoff(attempt)
+		if delay < 0 || delay > expectedMax {
+			t.Errorf("Expected delay for attempt #%d in [0, %v] but got %v", attempt, expectedMax, delay)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tcs := []struct {
+		value         string
+		expectedDelay time.Duration
+		expectedOK    bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{" 120 ", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"1.5", 0, false},
+		{"Fri, 04 Mar 2022 05:07:07 GMT", time.Minute, true},
+		{"Friday, 04-Mar-22 05:07:07 GMT", time.Minute, true},
+		{"Fri Mar  4 05:07:07 2022", time.Minute, true},
+		{"Fri, 04 Mar 2022 05:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.value, func(t *testing.T) {
+			delay, ok := parseRetryAfter(tc.value, now)
+			if diff := cmp.Diff(tc.expectedOK, ok); diff != "" {
+				t.Errorf("Actual ok diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedDelay, delay); diff != "" {
+				t.Errorf("Actual delay diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+
+	tcs := []struct {
+		name     string
+		header   http.Header
+		expectOK bool
+		min, max time.Duration
+	}{
+		{"Seconds", http.Header{"Retry-After": {"30"}}, true, 30 * time.Second, 30 * time.Second},
+		{"Date", http.Header{"Retry-After": {date}}, true, 58 * time.Minute, time.Hour},
+		{"Malformed", http.Header{"Retry-After": {"later"}}, false, 0, 0},
+		{"Missing", http.Header{}, false, 0, 0},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				t:    t,
+				doFn: respondWithHeader(http.StatusTooManyRequests, tc.header),
+			}).WithStatusCheck(true)
+
+			result := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do()
+
+			check := func(source string, delay time.Duration, ok bool) {
+				if ok != tc.expectOK {
+					t.Errorf("Expected ok %v from %s but got %v", tc.expectOK, source, ok)
+				}
+				if delay < tc.min || delay > tc.max {
+					t.Errorf("Expected delay from %s in [%v, %v] but got %v", source, tc.min, tc.max, delay)
+				}
+			}
+
+			delay, ok := result.RetryAfter()
+			check("result", delay, ok)
+
+			_, err := result.Response()
+			var httpErr *Error
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("Expected error '%v' to wrap an *Error", err)
+			}
+			delay, ok = httpErr.RetryAfter()
+			check("error", delay, ok)
+		})
+	}
+
+	t.Run("ErrorWithRetryAfter", func(t *testing.T) {
+		delay, ok := ErrServiceUnavailable.WithRetryAfter(time.Minute).RetryAfter()
+		if !ok || delay != time.Minute {
+			t.Errorf("Expected delay %v but got %v (ok: %v)", time.Minute, delay, ok)
+		}
+	})
+
+	t.Run("NoResponse", func(t *testing.T) {
+		c := NewClient(&mock{t: t, doFn: respondWith(0, nil, fmt.Errorf("injected error"))})
+		if _, ok := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().RetryAfter(); ok {
+			t.Errorf("Did not expect a delay without a response")
+		}
+	})
+}
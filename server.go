@@ -0,0 +1,255 @@
+package rhttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// WriteError writes the provided error to the response writer. If the error
+// is (or wraps) an `*Error` with a 4xx or 5xx status code, that status code
+// and message are written, along with any headers of the error, such as
+// `Retry-After`. Any other error is written as a generic `ErrInternalServer`,
+// so as not to leak internal details to the client. Likewise, an `*Error`
+// produced by a `Client` for an upstream response is written as a generic
+// `ErrBadGateway`, since its message and extensions are those of the upstream
+// service. The error is written as a problem details document (RFC 9457) if
+// the request's `Accept` header prefers JSON, and as plain text otherwise.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	httpErr := resolveError(err)
+
+	header := w.Header()
+	for key, values := range httpErr.Header {
+		header[key] = append([]string(nil), values...)
+	}
+
+	contentType := negotiateErrorContentType(r.Header.Get("Accept"))
+	body := []byte(httpErr.Message + "\n")
+	if contentType != "text/plain" {
+		buf, err := json.Marshal(httpErr)
+		if err == nil {
+			body = append(buf, '\n')
+		} else {
+			contentType = "text/plain"
+		}
+	}
+	if contentType == "text/plain" {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	header.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(httpErr.StatusCode)
+
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+}
+
+// resolveError determines the `*Error` to write for the provided error
+func resolveError(err error) *Error {
+	var httpErr *Error
+	if !errors.As(err, &httpErr) || httpErr == nil || httpErr.StatusCode < 400 || httpErr.StatusCode > 599 {
+		return ErrInternalServer
+	}
+	if httpErr.fromUpstream() {
+		return ErrBadGateway
+	}
+	return httpErr
+}
+
+// HandlerFunc adapts a function that returns an error into an `http.Handler`.
+// A returned error is written with `WriteError` (unless the function had
+// already written the response header) and a panic is recovered and written
+// as `ErrInternalServer`.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+var _ http.Handler = HandlerFunc(nil)
+
+// ServeHTTP calls the underlying function and writes any returned error
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveWithErrors(f, nil, w, r)
+}
+
+// ErrorLogger is notified of server-side errors, i.e. errors written as a 5xx
+// response, recovered panics, and errors that could not be written because
+// the response header had already been written
+type ErrorLogger func(r *http.Request, err error)
+
+// WithErrorLogger adapts the function into an `http.Handler` like
+// `ServeHTTP`, which also notifies the provided logger of server-side errors
+func (f HandlerFunc) WithErrorLogger(logger ErrorLogger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveWithErrors(f, logger, w, r)
+	})
+}
+
+// serveWithErrors implements `HandlerFunc`, notifying the logger (if any) of
+// server-side errors
+func serveWithErrors(f HandlerFunc, logger ErrorLogger, w http.ResponseWriter, r *http.Request) {
+	tw := &trackingResponseWriter{ResponseWriter: w}
+
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+
+		// let net/http handle its own sentinel for aborting a response
+		if p == http.ErrAbortHandler {
+			panic(p)
+		}
+
+		err := fmt.Errorf("recovered panic: %v\n%s", p, debug.Stack())
+		if logger != nil {
+			logger(r, err)
+		}
+		if !tw.wroteHeader {
+			WriteError(tw, r, ErrInternalServer)
+		}
+	}()
+
+	err := f(tw, r)
+	if err == nil {
+		return
+	}
+
+	if tw.wroteHeader {
+		if logger != nil {
+			logger(r, fmt.Errorf("failed to write error after response header was written: %w", err))
+		}
+		return
+	}
+
+	if logger != nil && resolveError(err).StatusCode >= 500 {
+		logger(r, err)
+	}
+	WriteError(tw, r, err)
+}
+
+// trackingResponseWriter records whether the response header has been written
+type trackingResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader records and writes the response header
+func (w *trackingResponseWriter) WriteHeader(statusCode int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records that the response header is written implicitly and writes the
+// response body
+func (w *trackingResponseWriter) Write(buf []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(buf)
+}
+
+// Flush implements `http.Flusher` if the wrapped writer does
+func (w *trackingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Hijack implements `http.Hijacker` if the wrapped writer does. Once the
+// connection is hijacked, no error may be written to it.
+func (w *trackingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped writer, so that its other optional interfaces
+// remain reachable, e.g. by `http.ResponseController` on go1.20 or later
+func (w *trackingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// errorContentTypes are the content types in which an error may be written,
+// in order of preference when the client accepts several equally
+var errorContentTypes = []string{"text/plain", ProblemContentType, "application/json"}
+
+// negotiateErrorContentType selects the content type in which to write an
+// error, per the provided `Accept` header. Each content type is assigned the
+// quality value of the most specific media range that matches it; the content
+// type with the highest quality value is selected, with ties broken by the
+// specificity of the match and then by `errorContentTypes`.
+func negotiateErrorContentType(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return errorContentTypes[0]
+	}
+
+	best, bestQuality, bestSpecificity := errorContentTypes[0], -1.0, -1
+	for _, contentType := range errorContentTypes {
+		quality, specificity := acceptQuality(accept, contentType)
+		if quality <= 0 {
+			continue
+		}
+
+		if quality > bestQuality || quality == bestQuality && specificity > bestSpecificity {
+			best, bestQuality, bestSpecificity = contentType, quality, specificity
+		}
+	}
+
+	return best
+}
+
+// acceptQuality returns the quality value that the `Accept` header assigns to
+// the content type, along with the specificity of the matching media range
+// (0 for `*/*`, 1 for `type/*` & 2 for `type/subtype`). The quality value is
+// zero if no media range matches.
+func acceptQuality(accept string, contentType string) (float64, int) {
+	quality, specificity := 0.0, -1
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		var s int
+		switch {
+		case mediaType == "*/*":
+			s = 0
+		case strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(mediaType, "*")):
+			s = 1
+		case mediaType == contentType:
+			s = 2
+		default:
+			continue
+		}
+
+		if s <= specificity {
+			continue
+		}
+
+		q := 1.0
+		if qstr, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(qstr, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		quality, specificity = q, s
+	}
+
+	return quality, specificity
+}
@@ -0,0 +1,320 @@
+package rhttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteError(t *testing.T) {
+	tcs := []struct {
+		name           string
+		method         string
+		accept         string
+		err            error
+		expectedStatus int
+		expectedHeader http.Header
+		expectedBody   string
+	}{
+		{
+			name:           "Text",
+			err:            ErrNotFound.New("no such user"),
+			expectedStatus: http.StatusNotFound,
+			expectedHeader: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			expectedBody:   "no such user\n",
+		},
+		{
+			name:           "Wrapped",
+			err:            fmt.Errorf("failed to find user: %w", ErrNotFound.New("no such user")),
+			expectedStatus: http.StatusNotFound,
+			expectedHeader: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			expectedBody:   "no such user\n",
+		},
+		{
+			name:           "UnknownError",
+			err:            fmt.Errorf("database password is hunter2"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedHeader: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			expectedBody:   "Internal Server Error\n",
+		},
+		{
+			name: "UpstreamError",
+			err: fmt.Errorf("failed to fetch user: %w", &Error{
+				StatusCode:     http.StatusInternalServerError,
+				Message:        "panic: db password=hunter2",
+				Extensions:     map[string]interface{}{"trace": "internal"},
+				Method:         http.MethodGet,
+				URL:            "http://users.internal/users/42",
+				ResponseHeader: http.Header{"Retry-After": {"30"}},
+			}),
+			expectedStatus: http.StatusBadGateway,
+			expectedHeader: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			expectedBody:   "Bad Gateway\n",
+		},
+		{
+			name: "UpstreamErrorWrappedByServerError",
+			err: ErrNotFound.New("no such user").Wrap(&Error{
+				StatusCode:     http.StatusNotFound,
+				Message:        "panic: db password=hunter2",
+				Method:         http.MethodGet,
+				URL:            "http://users.internal/users/42",
+				ResponseHeader: http.Header{},
+			}),
+			expectedStatus: http.StatusNotFound,
+			expectedHeader: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			expectedBody:   "no such user\n",
+		},
+		{
+			name:           "NonErrorStatusCode",
+			err:            NewError(http.StatusOK, "ok"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedHeader: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			expectedBody:   "Internal Server Error\n",
+		},
+		{
+			name:           "ProblemJSON",
+			accept:         "application/problem+json",
+			err:            ErrConflict.New("already exists"),
+			expectedStatus: http.StatusConflict,
+			expectedHeader: http.Header{"Content-Type": {"application/problem+json"}},
+			expectedBody:   `{"detail":"already exists","status":409,"title":"Conflict"}` + "\n",
+		},
+		{
+			name:           "JSON",
+			accept:         "text/html;q=0.9, application/json",
+			err:            ErrConflict.New("already exists"),
+			expectedStatus: http.StatusConflict,
+			expectedHeader: http.Header{"Content-Type": {"application/json"}},
+			expectedBody:   `{"detail":"already exists","status":409,"title":"Conflict"}` + "\n",
+		},
+		{
+			name:           "BrowserPrefersText",
+			accept:         "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			err:            ErrConflict.New("already exists"),
+			expectedStatus: http.StatusConflict,
+			expectedHeader: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			expectedBody:   "already exists\n",
+		},
+		{
+			name:           "TextRejected",
+			accept:         "text/plain;q=0, */*",
+			err:            ErrConflict.New("already exists"),
+			expectedStatus: http.StatusConflict,
+			expectedHeader: http.Header{"Content-Type": {"application/problem+json"}},
+			expectedBody:   `{"detail":"already exists","status":409,"title":"Conflict"}` + "\n",
+		},
+		{
+			name:           "RetryAfter",
+			err:            ErrServiceUnavailable.WithRetryAfter(1500 * time.Millisecond),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHeader: http.Header{
+				"Content-Type": {"text/plain; charset=utf-8"},
+				"Retry-After":  {"2"},
+			},
+			expectedBody: "Service Unavailable\n",
+		},
+		{
+			name:           "Head",
+			method:         http.MethodHead,
+			err:            ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedHeader: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, "/", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			w := httptest.NewRecorder()
+			WriteError(w, req, tc.err)
+
+			if diff := cmp.Diff(tc.expectedStatus, w.Code); diff != "" {
+				t.Errorf("Actual status code diverges from expectation (-want +got): %s", diff)
+			}
+
+			for key := range tc.expectedHeader {
+				if diff := cmp.Diff(tc.expectedHeader.Values(key), w.Header().Values(key)); diff != "" {
+					t.Errorf("Actual header '%s' diverges from expectation (-want +got): %s", key, diff)
+				}
+			}
+
+			if diff := cmp.Diff(tc.expectedBody, w.Body.String()); diff != "" {
+				t.Errorf("Actual body diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+
+	t.Run("DoesNotModifyPackageErrors", func(t *testing.T) {
+		_ = ErrServiceUnavailable.WithRetryAfter(time.Second).WithHeader("X-Other", "1")
+		if ErrServiceUnavailable.Header != nil {
+			t.Errorf("Expected the package-defined error to remain unmodified")
+		}
+	})
+}
+
+func TestWriteErrorFromClient(t *testing.T) {
+	c := NewClient(&mock{
+		t: t,
+		doFn: func(req *http.Request) (*http.Response, error) {
+			resp, err := respondWith(http.StatusInternalServerError, []byte(`{"title":"panic","detail":"db password=hunter2","trace":"internal"}`), nil)(req)
+			resp.Header = http.Header{"Content-Type": {ProblemContentType}}
+			return resp, err
+		},
+	}).WithStatusCheck(true)
+
+	_, err := c.GET(&url.URL{Scheme: "http", Host: "users.internal"}).Do().Response()
+	if err == nil {
+		t.Fatalf("Expected a status error")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	WriteError(w, req, err)
+
+	if diff := cmp.Diff(http.StatusBadGateway, w.Code); diff != "" {
+		t.Errorf("Actual status code diverges from expectation (-want +got): %s", diff)
+	}
+	if strings.Contains(w.Body.String(), "hunter2") || strings.Contains(w.Body.String(), "internal") {
+		t.Errorf("Did not expect the upstream error to be reflected, got: %s", w.Body.String())
+	}
+}
+
+func TestHandlerFunc(t *testing.T) {
+	tcs := []struct {
+		name           string
+		fn             HandlerFunc
+		expectedStatus int
+		expectedBody   string
+		expectedLogged bool
+	}{
+		{
+			name: "Success",
+			fn: func(w http.ResponseWriter, r *http.Request) error {
+				_, err := w.Write([]byte("ok"))
+				return err
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ok",
+		},
+		{
+			name: "ClientError",
+			fn: func(w http.ResponseWriter, r *http.Request) error {
+				return ErrNotFound.Newf("no user '%s'", "rob")
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "no user 'rob'\n",
+		},
+		{
+			name: "ServerError",
+			fn: func(w http.ResponseWriter, r *http.Request) error {
+				return fmt.Errorf("connection refused")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Internal Server Error\n",
+			expectedLogged: true,
+		},
+		{
+			name: "Panic",
+			fn: func(w http.ResponseWriter, r *http.Request) error {
+				panic("oops")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Internal Server Error\n",
+			expectedLogged: true,
+		},
+		{
+			name: "ErrorAfterHeaderWritten",
+			fn: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusAccepted)
+				return ErrConflict
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedLogged: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, withLogger := range []bool{false, true} {
+				var logged []error
+				var handler http.Handler = tc.fn
+				if withLogger {
+					handler = tc.fn.WithErrorLogger(func(r *http.Request, err error) {
+						logged = append(logged, err)
+					})
+				}
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+				if diff := cmp.Diff(tc.expectedStatus, w.Code); diff != "" {
+					t.Errorf("Actual status code diverges from expectation (-want +got): %s", diff)
+				}
+				if diff := cmp.Diff(tc.expectedBody, w.Body.String()); diff != "" {
+					t.Errorf("Actual body diverges from expectation (-want +got): %s", diff)
+				}
+				if withLogger && (len(logged) > 0) != tc.expectedLogged {
+					t.Errorf("Expected logged errors: %v, but got '%v'", tc.expectedLogged, logged)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerFuncHijack(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			return fmt.Errorf("response writer does not implement http.Hijacker")
+		}
+
+		conn, rw, err := hijacker.Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = rw.Flush()
+
+		// no error may be written to the hijacked connection
+		return ErrConflict
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(http.StatusOK, resp.StatusCode); diff != "" {
+		t.Errorf("Actual status code diverges from expectation (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff("hijacked", string(buf)); diff != "" {
+		t.Errorf("Actual body diverges from expectation (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,258 @@
+package rhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrStopStream may be returned by the callback of `Result.DecodeJSONStream`
+// to stop streaming early, without failing
+var ErrStopStream = errors.New("stop stream")
+
+// DecodeJSONStream decodes a response body that streams newline-delimited
+// JSON values (NDJSON / JSON Lines) one value at a time, so that the body is
+// never buffered in full. The callback is invoked once per value with a
+// decoder positioned at that value, which it must decode, e.g. with
+// `dec.Decode(&v)`. Returning `ErrStopStream` stops the stream early and any
+// other error aborts it. The provided options configure the decoder as for
+// `DecodeJSON`. If there was an error anywhere in the chain, it is returned.
+// As long as an HTTP response was generated, it is returned. However, note
+// that this method reads and closes the response body. This method terminates
+// a call chain.
+func (r *Result) DecodeJSONStream(fn func(dec *json.Decoder) error, opts ...DecodeOption) (*http.Response, error) {
+	return r.decodeJSONStream(false, fn, opts)
+}
+
+// DecodeJSONArrayStream is like `DecodeJSONStream`, for a response body that is
+// a top-level JSON array, whose elements are decoded one at a time
+func (r *Result) DecodeJSONArrayStream(fn func(dec *json.Decoder) error, opts ...DecodeOption) (*http.Response, error) {
+	return r.decodeJSONStream(true, fn, opts)
+}
+
+// decodeJSONStream implements `DecodeJSONStream` and `DecodeJSONArrayStream`
+func (r *Result) decodeJSONStream(array bool, fn func(dec *json.Decoder) error, opts []DecodeOption) (*http.Response, error) {
+	var v interface{}
+	if fn != nil {
+		v = fn
+	}
+
+	return r.decodeWith(v, opts, func(body io.Reader, o decodeOptions) error {
+		s := newJSONStream(body, array, o)
+		for {
+			more, err := s.next()
+			if err != nil || !more {
+				return err
+			}
+
+			offset := s.dec.InputOffset()
+			err = fn(s.dec)
+			if errors.Is(err, ErrStopStream) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			if s.dec.InputOffset() == offset {
+				return errors.New("stream callback did not decode a value")
+			}
+		}
+	})
+}
+
+// JSONIterator iterates over a response body that streams a sequence of JSON
+// values, decoding one value of type `T` at a time. It is vended by
+// `JSONLines` and `JSONArray`. The response body is closed once the iterator
+// is exhausted, fails, or is closed.
+type JSONIterator[T any] struct {
+	result   *Result
+	response *http.Response
+	stream   *jsonStream
+	value    T
+	err      error
+	done     bool
+}
+
+// JSONLines vends an iterator over the response body of the result, which
+// streams newline-delimited JSON values (NDJSON / JSON Lines). The values may
+// be of any type, including arrays. The provided options configure the
+// decoder as for `Result.DecodeJSON`. If there was an error anywhere in the
+// chain, the iterator yields no values and reports it from `Err`. Unless the
+// iterator is exhausted, it must be closed with `Close`.
+//
+//	it := rhttp.JSONLines[Event](c.GET(u).Do())
+//	defer it.Close()
+//	for it.Next() {
+//		event := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+func JSONLines[T any](result *Result, opts ...DecodeOption) *JSONIterator[T] {
+	return newJSONIterator[T](result, false, opts)
+}
+
+// JSONArray is like `JSONLines`, for a response body that is a top-level JSON
+// array, whose elements are decoded one at a time
+func JSONArray[T any](result *Result, opts ...DecodeOption) *JSONIterator[T] {
+	return newJSONIterator[T](result, true, opts)
+}
+
+// newJSONIterator implements `JSONLines` and `JSONArray`
+func newJSONIterator[T any](result *Result, array bool, opts []DecodeOption) *JSONIterator[T] {
+	it := &JSONIterator[T]{
+		result:   result,
+		response: result.response,
+	}
+
+	if result.err != nil {
+		it.fail(result.err)
+		return it
+	}
+
+	if it.response == nil {
+		it.fail(fmt.Errorf("expected a non-nil response for '%s %s'", result.request.method, result.request.u))
+		return it
+	}
+
+	o := newDecodeOptions(result.request.decodeOptions, opts)
+	err := o.checkContentType(it.response)
+	if err != nil {
+		it.fail(it.decodeError(err))
+		return it
+	}
+
+	it.stream = newJSONStream(it.response.Body, array, o)
+	return it
+}
+
+// Next advances the iterator to the next value, which is then available from
+// `Value`. It returns false once the stream is exhausted or fails, in which
+// case the error is available from `Err`.
+func (it *JSONIterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+
+	more, err := it.stream.next()
+	if err != nil {
+		it.fail(it.decodeError(err))
+		return false
+	}
+	if !more {
+		it.Close()
+		return false
+	}
+
+	var v T
+	err = it.stream.dec.Decode(&v)
+	if err != nil {
+		it.fail(it.decodeError(err))
+		return false
+	}
+
+	it.value = v
+	return true
+}
+
+// Value returns the value decoded by the last call to `Next`
+func (it *JSONIterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *JSONIterator[T]) Err() error {
+	return it.err
+}
+
+// Response returns the underlying HTTP response, if one was generated
+func (it *JSONIterator[T]) Response() *http.Response {
+	return it.response
+}
+
+// Close stops the iteration and closes the response body. It is safe to call
+// more than once.
+func (it *JSONIterator[T]) Close() error {
+	if it.done {
+		return nil
+	}
+
+	it.done = true
+	if it.response == nil || it.response.Body == nil {
+		return nil
+	}
+	return it.response.Body.Close()
+}
+
+// fail stops the iteration with the provided error
+func (it *JSONIterator[T]) fail(err error) {
+	it.err = err
+	it.Close()
+}
+
+// decodeError wraps an error decoding the response body
+func (it *JSONIterator[T]) decodeError(err error) error {
+	return fmt.Errorf("failed to decode the response body for '%s %s': %w", it.result.request.method, it.result.request.u, err)
+}
+
+// jsonStream reads a sequence of JSON values, which are either
+// whitespace-delimited or the elements of a top-level array
+type jsonStream struct {
+	dec     *json.Decoder
+	array   bool
+	started bool
+}
+
+// newJSONStream vends a `*jsonStream` reading from `r`, as configured
+func newJSONStream(r io.Reader, array bool, o decodeOptions) *jsonStream {
+	return &jsonStream{
+		dec:   o.newJSONDecoder(r),
+		array: array,
+	}
+}
+
+// next reports whether there is another value in the stream, positioning the
+// decoder at it. At the end of the stream, it verifies that nothing follows.
+func (s *jsonStream) next() (bool, error) {
+	if !s.started {
+		s.started = true
+
+		if s.array {
+			token, err := s.dec.Token()
+			if err == io.EOF {
+				return false, io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return false, err
+			}
+			if token != json.Delim('[') {
+				return false, fmt.Errorf("expected a top-level JSON array, got '%v'", token)
+			}
+		}
+	}
+
+	if s.dec.More() {
+		return true, nil
+	}
+
+	if s.array {
+		s.array = false
+		_, err := s.dec.Token()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	_, err := s.dec.Token()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, errors.New("unexpected data after the end of the stream")
+}
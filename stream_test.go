@@ -0,0 +1,313 @@
+package rhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var (
+	errStreamCallback = errors.New("stream callback error")
+	errStreamRequest  = errors.New("stream request error")
+)
+
+// respondWithStream responds with the provided body, which is wrapped so that
+// closing it may be observed
+func respondWithStream(body string) doFn {
+	return func(*http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/x-ndjson"}},
+			Body:       &trackingCloser{Reader: strings.NewReader(body)},
+		}, nil
+	}
+}
+
+var streamTestCases = []struct {
+	name        string
+	array       bool
+	body        string
+	expected    []payload
+	expectedErr error
+}{
+	{
+		name:     "NDJSON",
+		body:     "{\"Val1\":1,\"Val2\":\"a\"}\n{\"Val1\":2,\"Val2\":\"b\"}\n{\"Val1\":3,\"Val2\":\"c\"}\n",
+		expected: []payload{{1, "a"}, {2, "b"}, {3, "c"}},
+	},
+	{
+		name:     "NDJSONWithoutTrailingNewline",
+		body:     "{\"Val1\":1,\"Val2\":\"a\"}\r\n{\"Val1\":2,\"Val2\":\"b\"}",
+		expected: []payload{{1, "a"}, {2, "b"}},
+	},
+	{
+		name:     "Array",
+		array:    true,
+		body:     " [{\"Val1\":1,\"Val2\":\"a\"},\n{\"Val1\":2,\"Val2\":\"b\"}] \n",
+		expected: []payload{{1, "a"}, {2, "b"}},
+	},
+	{
+		name:  "EmptyArray",
+		array: true,
+		body:  "[]",
+	},
+	{
+		name: "Empty",
+		body: "",
+	},
+	{
+		name:        "Malformed",
+		body:        "{\"Val1\":1,\"Val2\":\"a\"}\n{\"Val1\":",
+		expected:    []payload{{1, "a"}},
+		expectedErr: cmpopts.AnyError,
+	},
+	{
+		name:        "UnterminatedArray",
+		array:       true,
+		body:        "[{\"Val1\":1,\"Val2\":\"a\"}",
+		expected:    []payload{{1, "a"}},
+		expectedErr: cmpopts.AnyError,
+	},
+	{
+		name:        "TrailingDataAfterArray",
+		array:       true,
+		body:        "[{\"Val1\":1,\"Val2\":\"a\"}] {}",
+		expected:    []payload{{1, "a"}},
+		expectedErr: cmpopts.AnyError,
+	},
+	{
+		name:        "ArrayExpected",
+		array:       true,
+		body:        "{\"Val1\":1,\"Val2\":\"a\"}\n",
+		expectedErr: cmpopts.AnyError,
+	},
+	{
+		name:        "EmptyArrayExpected",
+		array:       true,
+		body:        "",
+		expectedErr: cmpopts.AnyError,
+	},
+	{
+		name:        "ArrayNotExpected",
+		body:        "[{\"Val1\":1,\"Val2\":\"a\"}]",
+		expectedErr: cmpopts.AnyError,
+	},
+	{
+		name:        "TrailingDelimiter",
+		body:        "{\"Val1\":1,\"Val2\":\"a\"}}",
+		expected:    []payload{{1, "a"}},
+		expectedErr: cmpopts.AnyError,
+	},
+}
+
+func TestDecodeJSONStream(t *testing.T) {
+	for _, tc := range streamTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp *http.Response
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					resp, _ = respondWithStream(tc.body)(req)
+					return resp, nil
+				},
+			})
+
+			result := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do()
+			decodeStream := result.DecodeJSONStream
+			if tc.array {
+				decodeStream = result.DecodeJSONArrayStream
+			}
+
+			var actual []payload
+			_, err := decodeStream(func(dec *json.Decoder) error {
+				var v payload
+				err := dec.Decode(&v)
+				if err == nil {
+					actual = append(actual, v)
+				}
+				return err
+			})
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Actual values diverge from expectation (-want +got): %s", diff)
+			}
+			if !resp.Body.(*trackingCloser).closed {
+				t.Errorf("Expected the response body to be closed")
+			}
+		})
+	}
+
+	body := "{\"Val1\":1,\"Val2\":\"a\"}\n{\"Val1\":2,\"Val2\":\"b\"}\n{\"Val1\":3,\"Val2\":\"c\"}\n"
+
+	callbackTcs := []struct {
+		name          string
+		callback      func(dec *json.Decoder) error
+		expectedCalls int
+		expectedErr   error
+	}{
+		{
+			name: "StopStream",
+			callback: func(dec *json.Decoder) error {
+				var v payload
+				if err := dec.Decode(&v); err != nil {
+					return err
+				}
+				if v.Val1 == 2 {
+					return ErrStopStream
+				}
+				return nil
+			},
+			expectedCalls: 2,
+		},
+		{
+			name: "CallbackError",
+			callback: func(dec *json.Decoder) error {
+				return errStreamCallback
+			},
+			expectedCalls: 1,
+			expectedErr:   errStreamCallback,
+		},
+		{
+			name: "CallbackDoesNotDecode",
+			callback: func(dec *json.Decoder) error {
+				return nil
+			},
+			expectedCalls: 1,
+			expectedErr:   cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range callbackTcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{t: t, doFn: respondWithStream(body)})
+
+			calls := 0
+			_, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().DecodeJSONStream(func(dec *json.Decoder) error {
+				calls++
+				return tc.callback(dec)
+			})
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedCalls, calls); diff != "" {
+				t.Errorf("Actual number of calls diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	for _, tc := range streamTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp *http.Response
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					resp, _ = respondWithStream(tc.body)(req)
+					return resp, nil
+				},
+			})
+
+			result := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do()
+			it := JSONLines[payload](result)
+			if tc.array {
+				it = JSONArray[payload](result)
+			}
+
+			var actual []payload
+			for it.Next() {
+				actual = append(actual, it.Value())
+			}
+			if diff := cmp.Diff(tc.expectedErr, it.Err(), cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Actual values diverge from expectation (-want +got): %s", diff)
+			}
+			if !resp.Body.(*trackingCloser).closed {
+				t.Errorf("Expected the response body to be closed once exhausted")
+			}
+		})
+	}
+
+	t.Run("ArrayValues", func(t *testing.T) {
+		c := NewClient(&mock{t: t, doFn: respondWithStream("[1,2]\n[3,4]\n")})
+
+		it := JSONLines[[]int](c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do())
+		defer it.Close()
+
+		var actual [][]int
+		for it.Next() {
+			actual = append(actual, it.Value())
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([][]int{{1, 2}, {3, 4}}, actual); diff != "" {
+			t.Errorf("Actual values diverge from expectation (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("EarlyClose", func(t *testing.T) {
+		var resp *http.Response
+		c := NewClient(&mock{
+			t: t,
+			doFn: func(req *http.Request) (*http.Response, error) {
+				resp, _ = respondWithStream("{\"Val1\":1}\n{\"Val1\":2}\n")(req)
+				return resp, nil
+			},
+		})
+
+		it := JSONLines[payload](c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do())
+		if !it.Next() {
+			t.Fatalf("Expected a value, got error: %v", it.Err())
+		}
+		if err := it.Close(); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if it.Next() {
+			t.Errorf("Did not expect a value after closing the iterator")
+		}
+		if !resp.Body.(*trackingCloser).closed {
+			t.Errorf("Expected the response body to be closed")
+		}
+		if err := it.Close(); err != nil {
+			t.Errorf("Unexpected error closing twice: %v", err)
+		}
+	})
+
+	t.Run("ChainError", func(t *testing.T) {
+		c := NewClient(&mock{t: t, doFn: respondWith(0, nil, errStreamRequest)})
+
+		it := JSONLines[payload](c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do())
+		if it.Next() {
+			t.Errorf("Did not expect a value")
+		}
+		if diff := cmp.Diff(errStreamRequest, it.Err(), cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("DecodeOptions", func(t *testing.T) {
+		c := NewClient(&mock{t: t, doFn: respondWithStream("{\"Val1\":1}\n{\"Val3\":2}\n")})
+
+		it := JSONLines[payload](c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do(), DisallowUnknownFields(true))
+		defer it.Close()
+
+		count := 0
+		for it.Next() {
+			count++
+		}
+		if count != 1 || it.Err() == nil {
+			t.Errorf("Expected one value followed by an error, got %d values and error: %v", count, it.Err())
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package rhttp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// resolveURL resolves the reference `ref` against the `base` url. Unlike
+// `url.URL.ResolveReference`, the path of a relative reference is always
+// appended to the base path (regardless of leading or trailing slashes) and
+// the query parameters of both urls are merged, with those of the reference
+// taking precedence. If `ref` is absolute or specifies its own host, or if
+// `base` is nil, a copy of `ref` is returned unchanged. Neither input is
+// mutated.
+func resolveURL(base, ref *url.URL) *url.URL {
+	if ref == nil {
+		return nil
+	}
+
+	if base == nil || ref.IsAbs() || ref.Host != "" {
+		u := *ref
+		return &u
+	}
+
+	u := *base
+	u.Path = joinPath(base.Path, ref.Path)
+	u.RawPath = ""
+	if base.RawPath != "" || ref.RawPath != "" {
+		u.RawPath = joinPath(base.EscapedPath(), ref.EscapedPath())
+	}
+
+	if ref.RawQuery != "" {
+		query := base.Query()
+		for key, values := range ref.Query() {
+			query[key] = values
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	u.Fragment = ref.Fragment
+	u.RawFragment = ref.RawFragment
+
+	return &u
+}
+
+// joinPath joins two url paths with exactly one slash between them
+func joinPath(base, ref string) string {
+	if ref == "" {
+		return base
+	}
+	if base == "" {
+		return ref
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(ref, "/")
+}
+
+// pathParamPattern matches a `{name}` placeholder within an escaped url path,
+// in which the braces are always percent-encoded
+var pathParamPattern = regexp.MustCompile(`%7[Bb]([A-Za-z0-9_.\-]+)%7[Dd]`)
+
+// pathParamNames returns the names of all placeholders in the url's path
+func pathParamNames(u *url.URL) []string {
+	var names []string
+	for _, match := range pathParamPattern.FindAllStringSubmatch(u.EscapedPath(), -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// fillPathParams returns a copy of the url where every `{name}` placeholder
+// in the path is replaced by the percent-escaped value for `name`. Since the
+// values are escaped as a single path segment, they can neither introduce
+// additional path segments nor additional placeholders. It is an error for a
+// placeholder to have no value.
+func fillPathParams(u *url.URL, params map[string]string) (*url.URL, error) {
+	var unfilled []string
+	escaped := pathParamPattern.ReplaceAllStringFunc(u.EscapedPath(), func(placeholder string) string {
+		name := pathParamPattern.FindStringSubmatch(placeholder)[1]
+		value, ok := params[name]
+		if !ok {
+			unfilled = append(unfilled, name)
+			return placeholder
+		}
+		return url.PathEscape(value)
+	})
+
+	if len(unfilled) > 0 {
+		return nil, fmt.Errorf("unfilled path parameters %q", unfilled)
+	}
+
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unescape path '%s': %w", escaped, err)
+	}
+
+	filled := *u
+	filled.Path = path
+	filled.RawPath = escaped
+
+	return &filled, nil
+}
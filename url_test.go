@@ -0,0 +1,224 @@
+package rhttp
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func mustParseURL(rawurl string, t *testing.T) *url.URL {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		t.Fatalf("Failed to parse url '%s': %v", rawurl, err)
+	}
+	return u
+}
+
+func TestResolveURL(t *testing.T) {
+	tcs := []struct {
+		name     string
+		base     string
+		ref      string
+		expected string
+	}{
+		{"NoBase", "", "/v1/users", "/v1/users"},
+		{"BaseWithoutPath", "https://example.com", "/v1/users", "https://example.com/v1/users"},
+		{"BaseWithPath", "https://example.com/api", "/v1/users", "https://example.com/api/v1/users"},
+		{"BaseWithTrailingSlash", "https://example.com/api/", "/v1/users", "https://example.com/api/v1/users"},
+		{"RefWithoutLeadingSlash", "https://example.com/api", "v1/users", "https://example.com/api/v1/users"},
+		{"RefWithTrailingSlash", "https://example.com/api", "v1/users/", "https://example.com/api/v1/users/"},
+		{"EmptyRef", "https://example.com/api", "", "https://example.com/api"},
+		{"BaseQueryKept", "https://example.com/api?key=k", "/v1/users", "https://example.com/api/v1/users?key=k"},
+		{"QueriesMerged", "https://example.com/api?key=k&a=1", "/v1/users?a=2&b=3", "https://example.com/api/v1/users?a=2&b=3&key=k"},
+		{"EscapedRef", "https://example.com/api", "/files/a%2Fb", "https://example.com/api/files/a%2Fb"},
+		{"AbsoluteRef", "https://example.com/api", "http://other.com/x", "http://other.com/x"},
+		{"SchemeRelativeRef", "https://example.com/api", "//other.com/x", "//other.com/x"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var base *url.URL
+			if tc.base != "" {
+				base = mustParseURL(tc.base, t)
+			}
+			ref := mustParseURL(tc.ref, t)
+			refBefore := ref.String()
+
+			actual := resolveURL(base, ref).String()
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Actual url diverges from expectation (-want +got): %s", diff)
+			}
+
+			if diff := cmp.Diff(refBefore, ref.String()); diff != "" {
+				t.Errorf("Reference url was mutated (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	var actual string
+	c := NewClient(&mock{
+		t: t,
+		doFn: func(req *http.Request) (*http.Response, error) {
+			actual = req.URL.String()
+			return respondWith(http.StatusOK, nil, nil)(req)
+		},
+	}).WithBaseURL(mustParseURL("https://example.com/api", t))
+
+	t.Run("Path", func(t *testing.T) {
+		_, err := c.GETPath("/v1/users/42").Do().Response()
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if diff := cmp.Diff("https://example.com/api/v1/users/42", actual); diff != "" {
+			t.Errorf("Actual url diverges from expectation (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("RelativeURL", func(t *testing.T) {
+		_, err := c.DELETE(&url.URL{Path: "v1/users/42"}).Do().Response()
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if diff := cmp.Diff("https://example.com/api/v1/users/42", actual); diff != "" {
+			t.Errorf("Actual url diverges from expectation (-want +got): %s", diff)
+		}
+	})
+
+	t.Run("UnparseablePath", func(t *testing.T) {
+		_, err := c.GETPath("%zz").Do().Response()
+		if err == nil {
+			t.Errorf("Expected an error for an unparseable path")
+		}
+	})
+}
+
+func TestPathParams(t *testing.T) {
+	tcs := []struct {
+		name        string
+		template    string
+		requestFn   requestFn
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "Single",
+			template: "https://example.com/users/{id}",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParam("id", "42")
+			},
+			expected: "https://example.com/users/42",
+		},
+		{
+			name:     "Map",
+			template: "https://example.com/orgs/{org}/repos/{repo}?page=2",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParams(map[string]string{"org": "acme", "repo": "rhttp"})
+			},
+			expected: "https://example.com/orgs/acme/repos/rhttp?page=2",
+		},
+		{
+			name:     "RepeatedPlaceholder",
+			template: "https://example.com/{id}/{id}",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParam("id", "x")
+			},
+			expected: "https://example.com/x/x",
+		},
+		{
+			name:     "Escaped",
+			template: "https://example.com/files/{name}",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParam("name", "../a b/{c}?d")
+			},
+			expected: "https://example.com/files/..%2Fa%20b%2F%7Bc%7D%3Fd",
+		},
+		{
+			name:     "MissingPlaceholder",
+			template: "https://example.com/users/{id}",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParam("org", "acme")
+			},
+			expectedErr: true,
+		},
+		{
+			name:     "EmptyValue",
+			template: "https://example.com/users/{id}",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParam("id", "")
+			},
+			expectedErr: true,
+		},
+		{
+			name:     "DotSegment",
+			template: "https://example.com/orgs/{org}/admin",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParam("org", ".")
+			},
+			expectedErr: true,
+		},
+		{
+			name:     "DotDotSegment",
+			template: "https://example.com/orgs/{org}/admin",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParam("org", "..")
+			},
+			expectedErr: true,
+		},
+		{
+			name:     "DotDotSegmentInMap",
+			template: "https://example.com/orgs/{org}/repos/{repo}",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParams(map[string]string{"org": "acme", "repo": ".."})
+			},
+			expectedErr: true,
+		},
+		{
+			name:     "DotsWithinSegment",
+			template: "https://example.com/files/{name}",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParam("name", "...")
+			},
+			expected: "https://example.com/files/...",
+		},
+		{
+			name:     "Unfilled",
+			template: "https://example.com/orgs/{org}/repos/{repo}",
+			requestFn: func(r *Request) *Request {
+				return r.WithPathParam("org", "acme")
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual string
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					actual = req.URL.String()
+					return respondWith(http.StatusOK, nil, nil)(req)
+				},
+			})
+
+			_, err := tc.requestFn(c.GET(mustParseURL(tc.template, t))).Do().Response()
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("Actual url diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
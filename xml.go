@@ -0,0 +1,115 @@
+package rhttp
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+// xmlCodec is the `Codec` for `application/xml`, backed by `encoding/xml`
+type xmlCodec struct{}
+
+var _ Codec = xmlCodec{}
+
+// ContentType returns `application/xml; charset=utf-8`
+func (xmlCodec) ContentType() string {
+	return "application/xml; charset=utf-8"
+}
+
+// Encode writes the XML header followed by the XML encoding of `v` to `w`
+func (xmlCodec) Encode(w io.Writer, v interface{}) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// Decode reads the XML encoding of a value from `r` and stores it in `v`,
+// honoring the encoding named by the XML declaration, if any
+func (xmlCodec) Decode(r io.Reader, v interface{}) error {
+	return decodeXML(r, "", v)
+}
+
+// decodeXML reads the XML encoding of a value from `r` in the provided charset
+// and stores it in `v`. If the charset is empty, the encoding named by the XML
+// declaration is honored instead, defaulting to UTF-8.
+func decodeXML(r io.Reader, charset string, v interface{}) error {
+	if charset != "" {
+		var err error
+		r, err = charsetReader(charset, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charsetReader
+	if charset != "" {
+		// the input has already been transcoded to UTF-8, so the encoding
+		// named by the XML declaration no longer applies
+		dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
+	}
+
+	return dec.Decode(v)
+}
+
+// xmlCharset extracts the charset parameter from a content type, if any
+func xmlCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+// charsetReader returns a reader that transcodes the input from the named
+// charset to UTF-8. It supports UTF-8, US-ASCII and ISO-8859-1 (Latin-1).
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "latin-1", "l1", "cp819", "ibm819":
+		return &latin1Reader{r: bufio.NewReader(input)}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported charset '%s'", charset)
+}
+
+// latin1Reader transcodes ISO-8859-1 input to UTF-8. Every ISO-8859-1 byte
+// corresponds to the unicode code point of the same value.
+type latin1Reader struct {
+	r       io.ByteReader
+	pending []byte
+}
+
+// Read fills `p` with transcoded input
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	n := copy(p, l.pending)
+	l.pending = l.pending[n:]
+
+	var buf [utf8.UTFMax]byte
+	for n < len(p) {
+		b, err := l.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+
+		size := utf8.EncodeRune(buf[:], rune(b))
+		copied := copy(p[n:], buf[:size])
+		l.pending = append(l.pending, buf[copied:size]...)
+		n += copied
+	}
+
+	return n, nil
+}
@@ -0,0 +1,199 @@
+package rhttp
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type xmlPayload struct {
+	XMLName xml.Name `xml:"payload"`
+	Val1    int      `xml:"val1"`
+	Val2    string   `xml:"val2"`
+}
+
+func TestEncodeXML(t *testing.T) {
+	tcs := []struct {
+		name            string
+		requestFn       requestFn
+		requestCheckFns []requestCheckFn
+		expectedErr     error
+	}{
+		{
+			name: "Struct",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeXML(xmlPayload{Val1: 1, Val2: "a"})
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestBody(xml.Header + "<payload><val1>1</val1><val2>a</val2></payload>"),
+				checkRequestHeader("Content-Type", "application/xml; charset=utf-8"),
+			},
+		},
+		{
+			name: "ContentTypeOverridden",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeXML(xmlPayload{Val1: 1, Val2: "a"}).SetHeader("Content-Type", "text/xml")
+			},
+			requestCheckFns: []requestCheckFn{
+				checkRequestHeader("Content-Type", "text/xml"),
+			},
+		},
+		{
+			name: "EncodingFailure",
+			requestFn: func(r *Request) *Request {
+				return r.EncodeXML(make(chan int))
+			},
+			expectedErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				requestCheckFns: tc.requestCheckFns,
+				t:               t,
+				doFn:            respondWith(http.StatusOK, nil, nil),
+			})
+
+			_, err := tc.requestFn(c.POST(&url.URL{Scheme: "http", Host: "test.test.test"})).Do().Response()
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeXML(t *testing.T) {
+	tcs := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    xmlPayload
+		expectedErr error
+	}{
+		{
+			name:        "UTF8",
+			contentType: "application/xml; charset=utf-8",
+			body:        xml.Header + "<payload><val1>1</val1><val2>héllo</val2></payload>",
+			expected:    xmlPayload{XMLName: xml.Name{Local: "payload"}, Val1: 1, Val2: "héllo"},
+		},
+		{
+			name:        "Latin1Declaration",
+			contentType: "application/xml",
+			body:        "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><payload><val1>1</val1><val2>h\xe9llo</val2></payload>",
+			expected:    xmlPayload{XMLName: xml.Name{Local: "payload"}, Val1: 1, Val2: "héllo"},
+		},
+		{
+			name:        "Latin1ContentType",
+			contentType: "text/xml; charset=ISO-8859-1",
+			body:        "<payload><val1>1</val1><val2>h\xe9llo</val2></payload>",
+			expected:    xmlPayload{XMLName: xml.Name{Local: "payload"}, Val1: 1, Val2: "héllo"},
+		},
+		{
+			name:        "Latin1ContentTypeAndDeclaration",
+			contentType: "text/xml; charset=latin1",
+			body:        "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><payload><val1>1</val1><val2>h\xe9llo</val2></payload>",
+			expected:    xmlPayload{XMLName: xml.Name{Local: "payload"}, Val1: 1, Val2: "héllo"},
+		},
+		{
+			name:        "UnsupportedCharset",
+			contentType: "application/xml; charset=shift_jis",
+			body:        "<payload></payload>",
+			expectedErr: cmpopts.AnyError,
+		},
+		{
+			name:        "Malformed",
+			contentType: "application/xml",
+			body:        "<payload>",
+			expected:    xmlPayload{XMLName: xml.Name{Local: "payload"}},
+			expectedErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					resp, err := respondWith(http.StatusOK, []byte(tc.body), nil)(req)
+					resp.Header = http.Header{"Content-Type": {tc.contentType}}
+					return resp, err
+				},
+			})
+
+			var v xmlPayload
+			_, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().DecodeXML(&v)
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, v); diff != "" {
+				t.Errorf("Actual value diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+
+	t.Run("Codec", func(t *testing.T) {
+		c := NewClient(&mock{
+			t: t,
+			doFn: func(req *http.Request) (*http.Response, error) {
+				resp, err := respondWith(http.StatusOK, []byte("<payload><val1>1</val1></payload>"), nil)(req)
+				resp.Header = http.Header{"Content-Type": {"application/atom+xml"}}
+				return resp, err
+			},
+		})
+
+		var v xmlPayload
+		_, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().Decode(&v)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if diff := cmp.Diff(xmlPayload{XMLName: xml.Name{Local: "payload"}, Val1: 1}, v); diff != "" {
+			t.Errorf("Actual value diverges from expectation (-want +got): %s", diff)
+		}
+	})
+
+	for _, tc := range tcs {
+		t.Run("Codec/"+tc.name, func(t *testing.T) {
+			c := NewClient(&mock{
+				t: t,
+				doFn: func(req *http.Request) (*http.Response, error) {
+					resp, err := respondWith(http.StatusOK, []byte(tc.body), nil)(req)
+					resp.Header = http.Header{"Content-Type": {tc.contentType}}
+					return resp, err
+				},
+			})
+
+			var v xmlPayload
+			_, err := c.GET(&url.URL{Scheme: "http", Host: "test.test.test"}).Do().Decode(&v)
+			if diff := cmp.Diff(tc.expectedErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Actual error diverges from expectation (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, v); diff != "" {
+				t.Errorf("Actual value diverges from expectation (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestLatin1Reader(t *testing.T) {
+	r, err := charsetReader("ISO-8859-1", strings.NewReader("caf\xe9 \xff"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// read one byte at a time, to split multi-byte runes across reads
+	buf, err := io.ReadAll(iotest.OneByteReader(r))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if diff := cmp.Diff("café ÿ", string(buf)); diff != "" {
+		t.Errorf("Actual text diverges from expectation (-want +got): %s", diff)
+	}
+}